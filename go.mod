@@ -7,6 +7,8 @@ replace github.com/satori/go.uuid v1.2.0 => github.com/satori/go.uuid v1.2.1-0.2
 require (
 	github.com/Akachain/akc-go-sdk v1.0.9
 	github.com/Shopify/sarama v1.26.4 // indirect
+	github.com/decred/dcrd/dcrec/secp256k1/v4 v4.4.0
+	github.com/golang/protobuf v1.3.2
 	github.com/hashicorp/go-version v1.2.0 // indirect
 	github.com/hyperledger/fabric v1.4.4
 	github.com/mitchellh/mapstructure v1.1.2
@@ -3,7 +3,6 @@ package handler
 import (
 	"encoding/json"
 	"fmt"
-	"reflect"
 
 	"github.com/Akachain/akc-go-sdk/common"
 	"github.com/Akachain/akc-go-sdk/util"
@@ -20,6 +19,12 @@ type AdminHandler struct{}
 func (sah *AdminHandler) CreateAdmin(stub shim.ChaincodeStubInterface, adminStr string) (result *string, err error) {
 	common.Logger.Debugf("Input-data sent to CreateAdmin func: %+v\n", adminStr)
 
+	// Check role: SuperAdmin
+	err = hUtil.IsSuperAdmin(stub)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s", err.Error(), common.GetLine())
+	}
+
 	admin := new(model.Admin)
 	err = json.Unmarshal([]byte(adminStr), admin)
 	if err != nil { // Return error: Can't unmarshal json
@@ -29,6 +34,11 @@ func (sah *AdminHandler) CreateAdmin(stub shim.ChaincodeStubInterface, adminStr
 	admin.AdminID = hUtil.GenerateDocumentID(stub)
 	admin.Status = "Active"
 
+	// The Admin can only act with this identity, which isn't the creating SuperAdmin's one, so it must be given
+	if len(admin.MSPID) == 0 || len(admin.CertID) == 0 {
+		return nil, fmt.Errorf("%s %s", "MSPID and CertID can't be empty", common.GetLine())
+	}
+
 	common.Logger.Infof("Create Admin: %+v\n", admin)
 	err = util.Createdata(stub, model.AdminTable, []string{admin.AdminID}, &admin)
 	if err != nil { // Return error: Fail to insert data
@@ -55,6 +65,13 @@ func (sah *AdminHandler) GetAllAdmin(stub shim.ChaincodeStubInterface) (result *
 	return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], res.Message, common.GetLine())
 }
 
+// GetAdminPage returns a page of the Admins, pass the Bookmark of a page to get the next one
+func (sah *AdminHandler) GetAdminPage(stub shim.ChaincodeStubInterface, pageSize string, bookmark string) (result *string, err error) {
+	common.Logger.Debugf("Input-data sent to GetAdminPage func: %+v %+v\n", pageSize, bookmark)
+
+	return getPage(stub, model.AdminTable, func() interface{} { return new(model.Admin) }, pageSize, bookmark)
+}
+
 // GetAdminByID ...
 func (sah *AdminHandler) GetAdminByID(stub shim.ChaincodeStubInterface, adminID string) (result *string, err error) {
 	common.Logger.Debugf("Input-data sent to GetAdminByID func: %+v\n", adminID)
@@ -78,6 +95,14 @@ func (sah *AdminHandler) GetAdminByID(stub shim.ChaincodeStubInterface, adminID
 	return result, nil
 }
 
+// adminUpdateRules lists the Admin fields that can be changed by UpdateAdmin
+var adminUpdateRules = map[string]fieldRule{
+	"Name":   {},
+	"Status": {Roles: []string{hUtil.RoleSuperAdmin}},
+	"MSPID":  {Roles: []string{hUtil.RoleSuperAdmin}},
+	"CertID": {Roles: []string{hUtil.RoleSuperAdmin}},
+}
+
 //UpdateAdmin ...
 func (sah *AdminHandler) UpdateAdmin(stub shim.ChaincodeStubInterface, adminStr string) (result *string, err error) {
 	common.Logger.Debugf("Input-data sent to UpdateAdmin func: %+v\n", adminStr)
@@ -89,31 +114,38 @@ func (sah *AdminHandler) UpdateAdmin(stub shim.ChaincodeStubInterface, adminStr
 	}
 
 	if len(newAdmin.AdminID) == 0 {
-		return nil, fmt.Errorf("%s %s", "This ApprovalID can't be empty", common.GetLine())
+		return nil, fmt.Errorf("%s %s", "This AdminID can't be empty", common.GetLine())
 	}
 
 	// Get admin information
-	rawAdmin, err := util.Getdatabyid(stub, newAdmin.AdminID, model.AdminTable)
+	admin := new(model.Admin)
+	err = getRecord(stub, model.AdminTable, []string{newAdmin.AdminID}, admin)
 	if err != nil {
 		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
 	}
 
-	admin := new(model.Admin)
-	mapstructure.Decode(rawAdmin, admin)
-
-	// Filter fields needed to update
-	newAdminValue := reflect.ValueOf(newAdmin).Elem()
-	adminValue := reflect.ValueOf(admin).Elem()
-	for i := 0; i < newAdminValue.NumField(); i++ {
-		fieldName := newAdminValue.Type().Field(i).Name
-		if len(newAdminValue.Field(i).String()) > 0 {
-			field := adminValue.FieldByName(fieldName)
-			if field.CanSet() {
-				field.SetString(newAdminValue.Field(i).String())
-			}
+	// Only the Admin or a SuperAdmin can change the record
+	err = hUtil.IsInvoker(stub, admin.MSPID, admin.CertID)
+	if err != nil {
+		err = hUtil.IsSuperAdmin(stub)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s %s", "Only the Admin or a SuperAdmin can update it.", err.Error(), common.GetLine())
 		}
 	}
 
+	// Only copy the fields allowed to be updated
+	err = applyUpdate(stub, adminStr, admin, []string{"AdminID"}, adminUpdateRules)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s", err.Error(), common.GetLine())
+	}
+
+	if admin.Status != "Active" && admin.Status != "Inactive" {
+		return nil, fmt.Errorf("Invalid Admin status %s %s", admin.Status, common.GetLine())
+	}
+	if len(admin.MSPID) == 0 || len(admin.CertID) == 0 {
+		return nil, fmt.Errorf("%s %s", "MSPID and CertID can't be empty", common.GetLine())
+	}
+
 	err = util.Changeinfo(stub, model.AdminTable, []string{admin.AdminID}, admin)
 	if err != nil { // Return error: Fail to Update data
 		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR5], err.Error(), common.GetLine())
@@ -1,15 +1,11 @@
 package handler
 
 import (
-	"crypto/ecdsa"
 	"crypto/sha256"
-	"crypto/x509"
 	"encoding/base64"
 	"encoding/json"
-	"encoding/pem"
 	"errors"
 	"fmt"
-	"reflect"
 	"strings"
 	"time"
 
@@ -17,14 +13,15 @@ import (
 	"github.com/Akachain/akc-go-sdk/util"
 	"github.com/Akachain/hstx-go-sdk/model"
 	hUtil "github.com/Akachain/hstx-go-sdk/utils"
-	"github.com/hyperledger/fabric/bccsp/utils"
 	"github.com/hyperledger/fabric/core/chaincode/shim"
-	"github.com/mitchellh/mapstructure"
 )
 
 // ApprovalHandler ...
 type ApprovalHandler struct{}
 
+// challengeTTL is how long a challenge issued by RequestApprovalChallenge can be used
+const challengeTTL = 5 * time.Minute
+
 // CreateApproval ...
 func (sah *ApprovalHandler) CreateApproval(stub shim.ChaincodeStubInterface, approvalStr string) (result *string, err error) {
 	common.Logger.Debugf("Input-data sent to CreateApproval func: %+v\n", approvalStr)
@@ -42,10 +39,10 @@ func (sah *ApprovalHandler) CreateApproval(stub shim.ChaincodeStubInterface, app
 		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR3], err.Error(), common.GetLine())
 	}
 
-	// Check SuperAdmin's status
-	err = sah.checkApproverStatus(stub, approval.ApproverID)
+	// Check the caller is the approver and the approver is active
+	err = sah.checkApprover(stub, approval.ApproverID)
 	if err != nil {
-		return nil, fmt.Errorf("%s %s %s", "This approver is not active", err.Error(), common.GetLine())
+		return nil, fmt.Errorf("%s %s", err.Error(), common.GetLine())
 	}
 
 	// Get proposal by approval.ProposalID
@@ -60,34 +57,66 @@ func (sah *ApprovalHandler) CreateApproval(stub shim.ChaincodeStubInterface, app
 		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR3], err.Error(), common.GetLine())
 	}
 
-	// Check whether the proposal was rejected or not
-	if strings.Compare("Rejected", proposal.Status) == 0 {
-		return nil, fmt.Errorf("%s %s", "The proposal was rejected", common.GetLine())
+	// Only Pending and Approved proposals can be signed
+	if proposal.Status != model.ProposalPending && proposal.Status != model.ProposalApproved {
+		return nil, fmt.Errorf("The proposal can't be signed because it is %s %s", proposal.Status, common.GetLine())
+	}
+
+	// The proposal may restrict who can vote
+	err = sah.checkEligible(stub, &proposal, approval.ApproverID)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s", err.Error(), common.GetLine())
 	}
 
 	// Check this approver hasn't signed the proposal
-	compositeKey, _ := stub.CreateCompositeKey(model.ApprovalTable, []string{approval.ProposalID, approval.ApproverID})
-	rs, err := stub.GetState(compositeKey)
-	if err != nil { // Return error: Fail to get data
+	err = sah.checkNotSigned(stub, approval.ProposalID, approval.ApproverID)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s", err.Error(), common.GetLine())
+	}
+
+	now, err := hUtil.GetTxTime(stub)
+	if err != nil {
 		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
 	}
-	if len(rs) > 0 { // Return error: Only signing once
-		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR9], "This proposal had already been approved", common.GetLine())
+	if isExpired(&proposal, now) {
+		return nil, fmt.Errorf("%s %s", "The proposal has expired", common.GetLine())
+	}
+
+	// The approver must sign their outstanding challenge
+	challenge, err := sah.getChallenge(stub, approval.ProposalID, approval.ApproverID, now)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s", err.Error(), common.GetLine())
+	}
+	if len(approval.Challenge) > 0 && approval.Challenge != challenge.Nonce {
+		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR8], "The challenge doesn't match the outstanding one", common.GetLine())
+	}
+	approval.Challenge = challenge.Nonce
+
+	// The approver must sign the canonical payload of this proposal and decision, nothing else
+	payload, err := approvalPayload(&proposal, approval.Status, challenge.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s", err.Error(), common.GetLine())
+	}
+	if len(approval.Message) > 0 && approval.Message != base64.StdEncoding.EncodeToString(payload) {
+		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR8], "The signed message doesn't match the proposal", common.GetLine())
 	}
+	approval.Message = base64.StdEncoding.EncodeToString(payload)
 
 	// Verify signature with the singed message
-	err = sah.verifySignature(stub, approval.ApproverID, approval.Signature, approval.Message)
+	err = sah.verifyApproval(stub, approval, payload)
 	if err != nil { // Return error: Verify error
 		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR8], err.Error(), common.GetLine())
 	}
 
-	// Set approval.ApprovalID & approval.CreatedAt
-	approval.ApprovalID = hUtil.GenerateDocumentID(stub)
-	timestamp, err := stub.GetTxTimestamp()
+	// The challenge can only be used once
+	_, err = util.DeleteTableRow(stub, model.ChallengeTable, []string{approval.ProposalID, approval.ApproverID}, nil, util.FAIL_IF_MISSING)
 	if err != nil {
-		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR5], err.Error(), common.GetLine())
 	}
-	approval.CreatedAt = time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
+
+	// Set approval.ApprovalID & approval.CreatedAt
+	approval.ApprovalID = hUtil.GenerateDocumentID(stub)
+	approval.CreatedAt = now.Format(time.RFC3339)
 
 	// Create Approval
 	common.Logger.Infof("Creating Approval: %+v\n", approval)
@@ -95,9 +124,16 @@ func (sah *ApprovalHandler) CreateApproval(stub shim.ChaincodeStubInterface, app
 	if err != nil { // Return error: Fail to insert data
 		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR5], err.Error(), common.GetLine())
 	}
+	err = indexApproval(stub, approval)
+	if err != nil { // Return error: Fail to insert data
+		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR5], err.Error(), common.GetLine())
+	}
 
 	// Update proposal if necessary
-	sah.updateProposal(stub, approval)
+	err = sah.updateProposal(stub, approval, now)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s", err.Error(), common.GetLine())
+	}
 
 	bytes, err := json.Marshal(approval)
 	if err != nil { // Return error: Can't marshal json
@@ -110,6 +146,118 @@ func (sah *ApprovalHandler) CreateApproval(stub shim.ChaincodeStubInterface, app
 	return result, nil
 }
 
+// RequestApprovalChallenge issues a one-time nonce to include in the payload the approver signs.
+// A new request replaces the outstanding challenge of the approver for this proposal.
+func (sah *ApprovalHandler) RequestApprovalChallenge(stub shim.ChaincodeStubInterface, proposalID string, approverID string) (result *string, err error) {
+	common.Logger.Debugf("Input-data sent to RequestApprovalChallenge func: %+v %+v\n", proposalID, approverID)
+
+	// Check role: SuperAdmin
+	err = hUtil.IsSuperAdmin(stub)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s", err.Error(), common.GetLine())
+	}
+
+	// Check the caller is the approver and the approver is active
+	err = sah.checkApprover(stub, approverID)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s", err.Error(), common.GetLine())
+	}
+
+	proposal := new(model.Proposal)
+	err = getRecord(stub, model.ProposalTable, []string{proposalID}, proposal)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+	}
+	if proposal.Status != model.ProposalPending && proposal.Status != model.ProposalApproved {
+		return nil, fmt.Errorf("The proposal can't be signed because it is %s %s", proposal.Status, common.GetLine())
+	}
+
+	err = sah.checkEligible(stub, proposal, approverID)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s", err.Error(), common.GetLine())
+	}
+
+	err = sah.checkNotSigned(stub, proposalID, approverID)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s", err.Error(), common.GetLine())
+	}
+
+	now, err := hUtil.GetTxTime(stub)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+	}
+	if isExpired(proposal, now) {
+		return nil, fmt.Errorf("%s %s", "The proposal has expired", common.GetLine())
+	}
+
+	// The transaction ID is unique and unknown before the transaction is submitted
+	nonce := sha256.Sum256([]byte(strings.Join([]string{stub.GetTxID(), proposalID, approverID}, "\x00")))
+	challenge := model.Challenge{
+		ProposalID: proposalID,
+		ApproverID: approverID,
+		Nonce:      base64.StdEncoding.EncodeToString(nonce[:]),
+		CreatedAt:  now.Format(time.RFC3339),
+		ExpiresAt:  now.Add(challengeTTL).Format(time.RFC3339),
+	}
+
+	common.Logger.Infof("Issue Challenge: %+v\n", challenge)
+	err = util.UpdateExistingData(stub, model.ChallengeTable, []string{proposalID, approverID}, &challenge)
+	if err != nil { // Return error: Fail to insert data
+		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR5], err.Error(), common.GetLine())
+	}
+
+	bytes, err := json.Marshal(challenge)
+	if err != nil { // Return error: Can't marshal json
+		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR3], err.Error(), common.GetLine())
+	}
+	temp := ""
+	result = &temp
+	*result = string(bytes)
+
+	return result, nil
+}
+
+// GetApprovalPayload returns the payload an approver must sign to approve or reject a proposal,
+// including the outstanding challenge issued to the approver
+func (sah *ApprovalHandler) GetApprovalPayload(stub shim.ChaincodeStubInterface, proposalID string, approverID string, status string) (result *string, err error) {
+	common.Logger.Debugf("Input-data sent to GetApprovalPayload func: %+v %+v %+v\n", proposalID, approverID, status)
+
+	proposal := new(model.Proposal)
+	err = getRecord(stub, model.ProposalTable, []string{proposalID}, proposal)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+	}
+
+	now, err := hUtil.GetTxTime(stub)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+	}
+
+	challenge, err := sah.getChallenge(stub, proposalID, approverID, now)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s", err.Error(), common.GetLine())
+	}
+
+	payload, err := approvalPayload(proposal, status, challenge.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s", err.Error(), common.GetLine())
+	}
+	digest := sha256.Sum256(payload)
+
+	bytes, err := json.Marshal(map[string]string{
+		"Message": base64.StdEncoding.EncodeToString(payload),
+		"Digest":  base64.StdEncoding.EncodeToString(digest[:]),
+	})
+	if err != nil { // Return error: Can't marshal json
+		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR3], err.Error(), common.GetLine())
+	}
+	temp := ""
+	result = &temp
+	*result = string(bytes)
+
+	return result, nil
+}
+
 // GetAllApproval ...
 func (sah *ApprovalHandler) GetAllApproval(stub shim.ChaincodeStubInterface) (result *string, err error) {
 	res := util.GetAllData(stub, new(model.Approval), model.ApprovalTable)
@@ -119,17 +267,35 @@ func (sah *ApprovalHandler) GetAllApproval(stub shim.ChaincodeStubInterface) (re
 	return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], res.Message, common.GetLine())
 }
 
+// GetApprovalPage returns a page of the Approvals, pass the Bookmark of a page to get the next one
+func (sah *ApprovalHandler) GetApprovalPage(stub shim.ChaincodeStubInterface, pageSize string, bookmark string) (result *string, err error) {
+	common.Logger.Debugf("Input-data sent to GetApprovalPage func: %+v %+v\n", pageSize, bookmark)
+
+	return getPage(stub, model.ApprovalTable, func() interface{} { return new(model.Approval) }, pageSize, bookmark)
+}
+
 // GetApprovalByID ...
 func (sah *ApprovalHandler) GetApprovalByID(stub shim.ChaincodeStubInterface, approvalID string) (result *string, err error) {
 	common.Logger.Debugf("Input-data sent to GetApprovalByID func: %+v\n", approvalID)
 
-	rawApproval, err := util.Getdatabyid(stub, approvalID, model.ApprovalTable)
+	// Approvals are stored under the (ProposalID, ApproverID) key, the ApprovalID index holds it
+	var approvalKey []string
+	err = scanIndex(stub, model.ApprovalIDIndex, []string{approvalID}, func(keys []string) bool {
+		approvalKey = keys
+		return false
+	})
 	if err != nil {
 		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
 	}
+	if approvalKey == nil {
+		return nil, fmt.Errorf("%s Data with ID %s does not exist %s", common.ResCodeDict[common.ERR4], approvalID, common.GetLine())
+	}
 
 	approval := new(model.Approval)
-	mapstructure.Decode(rawApproval, approval)
+	err = getRecord(stub, model.ApprovalTable, approvalKey, approval)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+	}
 
 	bytes, err := json.Marshal(approval)
 	if err != nil { // Return error: Can't marshal json
@@ -142,53 +308,30 @@ func (sah *ApprovalHandler) GetApprovalByID(stub shim.ChaincodeStubInterface, ap
 	return result, nil
 }
 
-// UpdateApproval ...
-func (sah *ApprovalHandler) UpdateApproval(stub shim.ChaincodeStubInterface, approvalStr string) (result *string, err error) {
-	common.Logger.Debugf("Input-data sent to UpdateApproval func: %+v\n", approvalStr)
-
-	err = hUtil.IsSuperAdmin(stub)
-	if err != nil {
-		return nil, fmt.Errorf("%s %s", err.Error(), common.GetLine())
-	}
-
-	newApproval := new(model.Approval)
-	err = json.Unmarshal([]byte(approvalStr), newApproval)
-	if err != nil { // Return error: Can't unmarshal json
-		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR3], err.Error(), common.GetLine())
-	}
+// GetApprovalBySuperAdminID returns the approvals of a SuperAdmin, through the approver index
+func (sah *ApprovalHandler) GetApprovalBySuperAdminID(stub shim.ChaincodeStubInterface, superAdminID string) (result *string, err error) {
+	common.Logger.Debugf("Input-data sent to GetApprovalBySuperAdminID func: %+v\n", superAdminID)
 
-	if len(newApproval.ApprovalID) == 0 {
-		return nil, fmt.Errorf("%s %s", "This ApprovalID can't be empty", common.GetLine())
-	}
-
-	// Get approval information
-	rawApproval, err := util.Getdatabyid(stub, newApproval.ApprovalID, model.ApprovalTable)
+	proposalIDs := []string{}
+	err = scanIndex(stub, model.ApprovalApproverIndex, []string{superAdminID}, func(keys []string) bool {
+		proposalIDs = append(proposalIDs, keys[0])
+		return true
+	})
 	if err != nil {
 		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
 	}
 
-	approval := new(model.Approval)
-	mapstructure.Decode(rawApproval, approval)
-
-	// Filter fields needed to update
-	newApprovalValue := reflect.ValueOf(newApproval).Elem()
-	approvalValue := reflect.ValueOf(approval).Elem()
-	for i := 0; i < newApprovalValue.NumField(); i++ {
-		fieldName := newApprovalValue.Type().Field(i).Name
-		if len(newApprovalValue.Field(i).String()) > 0 {
-			field := approvalValue.FieldByName(fieldName)
-			if field.CanSet() {
-				field.SetString(newApprovalValue.Field(i).String())
-			}
+	approvalList := []model.Approval{}
+	for _, proposalID := range proposalIDs {
+		approval := new(model.Approval)
+		err = getRecord(stub, model.ApprovalTable, []string{proposalID, superAdminID}, approval)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
 		}
+		approvalList = append(approvalList, *approval)
 	}
 
-	err = util.Changeinfo(stub, model.ApprovalTable, []string{approval.ApprovalID}, approval)
-	if err != nil { // Return error: Fail to Update data
-		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR5], err.Error(), common.GetLine())
-	}
-
-	bytes, err := json.Marshal(approval)
+	bytes, err := json.Marshal(approvalList)
 	if err != nil { // Return error: Can't marshal json
 		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR3], err.Error(), common.GetLine())
 	}
@@ -199,150 +342,219 @@ func (sah *ApprovalHandler) UpdateApproval(stub shim.ChaincodeStubInterface, app
 	return result, nil
 }
 
-// checkApproverStatus func to check whether the SuperAdmin is active or inactive
-func (sah *ApprovalHandler) checkApproverStatus(stub shim.ChaincodeStubInterface, approverID string) error {
-	// Get approver by approval.ApproverID
-	superAdminStr, err := new(SuperAdminHandler).GetSuperAdminByID(stub, approverID)
+// checkNotSigned func to check the approver hasn't signed the proposal yet
+func (sah *ApprovalHandler) checkNotSigned(stub shim.ChaincodeStubInterface, proposalID string, approverID string) error {
+	compositeKey, _ := stub.CreateCompositeKey(model.ApprovalTable, []string{proposalID, approverID})
+	rs, err := stub.GetState(compositeKey)
+	if err != nil { // Return error: Fail to get data
+		return fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+	}
+	if len(rs) > 0 { // Return error: Only signing once
+		return fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR9], "This proposal had already been approved", common.GetLine())
+	}
+	return nil
+}
+
+// checkEligible func to check the approver can vote on the proposal
+func (sah *ApprovalHandler) checkEligible(stub shim.ChaincodeStubInterface, proposal *model.Proposal, approverID string) error {
+	eligible, err := isEligible(stub, proposal, approverID)
 	if err != nil {
 		return fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
 	}
+	if !eligible {
+		return fmt.Errorf("%s %s", "This approver is not eligible for the proposal", common.GetLine())
+	}
+	return nil
+}
+
+// getChallenge func to get the outstanding challenge issued to the approver, it fails if the challenge has expired
+func (sah *ApprovalHandler) getChallenge(stub shim.ChaincodeStubInterface, proposalID string, approverID string, now time.Time) (*model.Challenge, error) {
+	challenge := new(model.Challenge)
+	err := getRecord(stub, model.ChallengeTable, []string{proposalID, approverID}, challenge)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s %s", "No outstanding challenge, call RequestApprovalChallenge first.", err.Error(), common.GetLine())
+	}
+
+	expiresAt, err := time.Parse(time.RFC3339, challenge.ExpiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s", err.Error(), common.GetLine())
+	}
+	if now.After(expiresAt) {
+		return nil, fmt.Errorf("%s %s", "The challenge has expired, call RequestApprovalChallenge again.", common.GetLine())
+	}
+	return challenge, nil
+}
 
-	var superAdmin model.SuperAdmin
-	err = json.Unmarshal([]byte(*superAdminStr), &superAdmin)
+// checkApprover func to check the caller is the identity bound to the SuperAdmin and the SuperAdmin is active
+func (sah *ApprovalHandler) checkApprover(stub shim.ChaincodeStubInterface, approverID string) error {
+	// Get approver by approval.ApproverID
+	superAdmin, err := getBoundSuperAdmin(stub, approverID)
 	if err != nil {
-		return fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR3], err.Error(), common.GetLine())
+		return err
 	}
 
 	// Check SuperAdmin's status
-	if superAdmin.Status != "A" && superAdmin.Status != "Active" {
+	if !isActiveSuperAdmin(superAdmin) {
 		return fmt.Errorf("%s %s", "This approver is not active", common.GetLine())
 	}
 	// If the SuperAdmin is active, return nil
 	return nil
 }
 
-// verifySignature ...
-func (sah *ApprovalHandler) verifySignature(stub shim.ChaincodeStubInterface, approverID string, signature string, message string) error {
-	if len(approverID) == 0 {
+// approvalPayload func to build the canonical payload signed by an approver. It binds the signature to
+// the proposal's content and to the decision, so it can't be replayed for another proposal.
+func approvalPayload(proposal *model.Proposal, status string, challenge string) ([]byte, error) {
+	if status != model.ApprovalApproved && status != model.ApprovalRejected && status != model.ApprovalAbstain {
+		return nil, fmt.Errorf("Invalid approval status %s %s", status, common.GetLine())
+	}
+
+	payload := model.ApprovalPayload{
+		ProposalID:        proposal.ProposalID,
+		Message:           proposal.Message,
+		QuorumNumber:      proposal.QuorumNumber,
+		ThresholdWeight:   proposal.ThresholdWeight,
+		Policy:            proposal.Policy,
+		RejectionRule:     proposal.RejectionRule,
+		RejectionCount:    proposal.RejectionCount,
+		EligibleApprovers: proposal.EligibleApprovers,
+		EligibleGroups:    proposal.EligibleGroups,
+		CreatedBy:         proposal.CreatedBy,
+		CreatedAt:         proposal.CreatedAt,
+		Type:              proposal.Type,
+		Payload:           proposal.Payload,
+		Status:            status,
+		Challenge:         challenge,
+	}
+	bytes, err := json.Marshal(payload)
+	if err != nil { // Return error: Can't marshal json
+		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR3], err.Error(), common.GetLine())
+	}
+	return bytes, nil
+}
+
+// verifyApproval func to verify the approver's signature over the payload, either directly or through a WebAuthn assertion
+func (sah *ApprovalHandler) verifyApproval(stub shim.ChaincodeStubInterface, approval *model.Approval, payload []byte) error {
+	if len(approval.ApproverID) == 0 {
 		return errors.New("approverID is empty")
 	}
 
 	//get superAdmin information
-	rawSuperAdmin, err := util.Getdatabyid(stub, approverID, model.SuperAdminTable)
+	superAdmin := new(model.SuperAdmin)
+	err := getRecord(stub, model.SuperAdminTable, []string{approval.ApproverID}, superAdmin)
 	if err != nil {
 		return err
 	}
 
-	superAdmin := new(model.SuperAdmin)
-	mapstructure.Decode(rawSuperAdmin, superAdmin)
-
-	// Start verify
-	pkBytes := []byte(superAdmin.PublicKey)
-	pkBlock, _ := pem.Decode(pkBytes)
-	if pkBlock == nil {
-		return errors.New("can't decode public key")
-	}
-
-	rawPk, err := x509.ParsePKIXPublicKey(pkBlock.Bytes)
+	// Any active credential of the approver can sign
+	credential, err := signingCredential(superAdmin, approval.CredentialID)
 	if err != nil {
 		return err
 	}
 
-	pk := rawPk.(*ecdsa.PublicKey)
+	// Audits re-verify the signature with this version of the key
+	approval.KeyVersion = 0
+	if len(approval.CredentialID) == 0 {
+		approval.KeyVersion = currentKeyVersion(superAdmin)
+	}
+	// A later change of the approver's weight doesn't change the votes already cast
+	approval.Weight = superAdminWeight(superAdmin)
 
-	// SIGNATURE
-	signatureByte, err := base64.StdEncoding.DecodeString(signature)
-	if err != nil {
-		return err
+	switch approval.Format {
+	case "", model.FormatRaw:
+		approval.Format = model.FormatRaw
+		return hUtil.VerifySignature(credential.PublicKey, credential.SignatureScheme, credential.HashAlgorithm, payload, approval.Signature)
+
+	case model.FormatWebAuthn:
+		authenticatorData, err := hUtil.DecodeBase64(approval.AuthenticatorData)
+		if err != nil {
+			return err
+		}
+		clientDataJSON, err := hUtil.DecodeBase64(approval.ClientDataJSON)
+		if err != nil {
+			return err
+		}
+
+		// The relying party's challenge is the digest of the payload
+		digest := sha256.Sum256(payload)
+		signedData, signCount, err := hUtil.CheckWebAuthnAssertion(authenticatorData, clientDataJSON, superAdmin.RpID, digest[:], superAdmin.RequireUserVerification)
+		if err != nil {
+			return err
+		}
+		err = hUtil.CheckSignCount(credential.SignCount, signCount)
+		if err != nil {
+			return err
+		}
+		err = hUtil.VerifySignature(credential.PublicKey, credential.SignatureScheme, credential.HashAlgorithm, signedData, approval.Signature)
+		if err != nil {
+			return err
+		}
+
+		// Remember the counter of the authenticator to detect a clone at the next approval
+		credential.SignCount = signCount
+		if len(approval.CredentialID) == 0 {
+			superAdmin.SignCount = signCount
+		}
+		return util.Changeinfo(stub, model.SuperAdminTable, []string{superAdmin.SuperAdminID}, superAdmin)
 	}
+	return fmt.Errorf("Unknown signature format %s", approval.Format)
+}
 
-	R, S, err := utils.UnmarshalECDSASignature(signatureByte)
+// updateProposal func to reject the proposal under its rejection rule, or approve it once the quorum is reached
+// or its policy is satisfied
+func (sah *ApprovalHandler) updateProposal(stub shim.ChaincodeStubInterface, approval *model.Approval, now time.Time) error {
+	proposal := new(model.Proposal)
+	err := getRecord(stub, model.ProposalTable, []string{approval.ProposalID}, proposal)
 	if err != nil {
 		return err
 	}
 
-	// DATA
-	dataByte, err := base64.StdEncoding.DecodeString(message)
+	votes, err := tallyVotes(stub, proposal, approval)
 	if err != nil {
 		return err
 	}
 
-	hash := sha256.Sum256(dataByte)
-	var hashData = hash[:]
-
-	// VERIFY
-	checkedResult := ecdsa.Verify(pk, hashData, R, S)
-
-	if checkedResult {
-		return nil
-	}
-	return errors.New("verifying failed")
-}
-
-// updateProposal ...
-func (sah *ApprovalHandler) updateProposal(stub shim.ChaincodeStubInterface, approval *model.Approval) error {
-	rawProposal, err := util.Getdatabyid(stub, approval.ProposalID, model.ProposalTable)
+	rejected, err := rejectionReached(stub, proposal, votes)
 	if err != nil {
 		return err
 	}
-
-	proposal := new(model.Proposal)
-	mapstructure.Decode(rawProposal, proposal)
-
-	if strings.Compare(approval.Status, "Rejected") == 0 {
-		if strings.Compare(proposal.Status, "Commited") != 0 {
-			proposal.Status = approval.Status
-			proposal.UpdatedAt = approval.CreatedAt
-			bytes, err := json.Marshal(proposal)
-			if err != nil {
-				return err
-			}
-			new(ProposalHandler).UpdateProposal(stub, string(bytes))
-		}
+	if rejected {
+		return saveTransition(stub, proposal, model.ProposalRejected, now)
+	}
+	if proposal.Status != model.ProposalPending {
 		return nil
 	}
 
-	resIterator, err := hUtil.GetContainKey(stub, model.ApprovalTable, approval.ProposalID)
+	// Check the approvals reach the proposal's threshold, or its policy, to update the Proposal's satatus
+	reached, err := approvalReached(stub, proposal, votes.approved, votes.weight)
 	if err != nil {
 		return err
 	}
-	defer resIterator.Close()
-	count := 0
-	if approval.Status == "Approved" {
-		count++
+	if reached {
+		return saveTransition(stub, proposal, model.ProposalApproved, now)
 	}
-	for resIterator.HasNext() {
-		stateIterator, err := resIterator.Next()
-		if err != nil {
-			return err
-		}
-		approvalState := new(model.Approval)
-		err = json.Unmarshal(stateIterator.Value, approvalState)
-		if err != nil { // Convert JSON error
-			return err
-		}
+	return nil
+}
 
-		if strings.Compare("Approved", approvalState.Status) == 0 {
-			count++
-		}
+// approvalThreshold func to get what the approvals of the proposal must add up to: its ThresholdWeight, or its QuorumNumber
+func approvalThreshold(proposal *model.Proposal) int {
+	if proposal.ThresholdWeight > 0 {
+		return proposal.ThresholdWeight
 	}
-	// Check approved number >= proposal.QuorumNumber to update the Proposal's satatus
-	if count >= proposal.QuorumNumber {
-		rawProposal, err := util.Getdatabyid(stub, approval.ProposalID, model.ProposalTable)
-		if err != nil {
-			return err
-		}
-		proposal := new(model.Proposal)
-		mapstructure.Decode(rawProposal, proposal)
-		if strings.Compare(proposal.Status, "Pending") == 0 {
-			proposal.Status = "Approved"
-			proposal.UpdatedAt = approval.CreatedAt
-			bytes, err := json.Marshal(proposal)
-			if err != nil {
-				return err
-			}
-			new(ProposalHandler).UpdateProposal(stub, string(bytes))
-		}
+	return proposal.QuorumNumber
+}
+
+// voteWeight func to get what an approval adds to the proposal: the approver's weight if the proposal has a ThresholdWeight, else 1
+func voteWeight(proposal *model.Proposal, approval *model.Approval) int {
+	if proposal.ThresholdWeight == 0 || approval.Weight == 0 {
+		return 1
 	}
-	return nil
+	return approval.Weight
+}
+
+// superAdminWeight func to get the vote weight of the SuperAdmin, 1 unless it is set
+func superAdminWeight(superAdmin *model.SuperAdmin) int {
+	if superAdmin.Weight == 0 {
+		return 1
+	}
+	return superAdmin.Weight
 }
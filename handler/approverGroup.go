@@ -0,0 +1,244 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Akachain/akc-go-sdk/common"
+	"github.com/Akachain/akc-go-sdk/util"
+	"github.com/Akachain/hstx-go-sdk/model"
+	hUtil "github.com/Akachain/hstx-go-sdk/utils"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// ApproverGroupHandler ...
+type ApproverGroupHandler struct{}
+
+// approverGroupGovernanceRules lists the ApproverGroup fields that can be changed by an UpdateApproverGroup proposal
+var approverGroupGovernanceRules = map[string]fieldRule{
+	"Name":    {},
+	"Members": {},
+}
+
+// GetAllApproverGroup ...
+func (agh *ApproverGroupHandler) GetAllApproverGroup(stub shim.ChaincodeStubInterface) (result *string, err error) {
+	res := util.GetAllData(stub, new(model.ApproverGroup), model.ApproverGroupTable)
+	if res.Status == 200 {
+		return &res.Message, nil
+	}
+	return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], res.Message, common.GetLine())
+}
+
+// GetApproverGroupPage returns a page of the ApproverGroups, pass the Bookmark of a page to get the next one
+func (agh *ApproverGroupHandler) GetApproverGroupPage(stub shim.ChaincodeStubInterface, pageSize string, bookmark string) (result *string, err error) {
+	common.Logger.Debugf("Input-data sent to GetApproverGroupPage func: %+v %+v\n", pageSize, bookmark)
+
+	return getPage(stub, model.ApproverGroupTable, func() interface{} { return new(model.ApproverGroup) }, pageSize, bookmark)
+}
+
+// GetApproverGroupByID ...
+func (agh *ApproverGroupHandler) GetApproverGroupByID(stub shim.ChaincodeStubInterface, groupID string) (result *string, err error) {
+	common.Logger.Debugf("Input-data sent to GetApproverGroupByID func: %+v\n", groupID)
+
+	group := new(model.ApproverGroup)
+	err = getRecord(stub, model.ApproverGroupTable, []string{groupID}, group)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+	}
+
+	bytes, err := json.Marshal(group)
+	if err != nil { // Return error: Can't marshal json
+		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR3], err.Error(), common.GetLine())
+	}
+	temp := ""
+	result = &temp
+	*result = string(bytes)
+
+	return result, nil
+}
+
+// applyCreateApproverGroup func to check the group of a CreateApproverGroup proposal and create it if apply is true
+func applyCreateApproverGroup(stub shim.ChaincodeStubInterface, payload string, apply bool) error {
+	group := new(model.ApproverGroup)
+	err := json.Unmarshal([]byte(payload), group)
+	if err != nil { // Return error: Can't unmarshal json
+		return fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR3], err.Error(), common.GetLine())
+	}
+
+	// The policy syntax quotes the GroupID and reserves * for all the SuperAdmins
+	if len(group.GroupID) == 0 || group.GroupID == model.AllSuperAdmins || strings.Contains(group.GroupID, "'") {
+		return fmt.Errorf("Invalid GroupID %s %s", group.GroupID, common.GetLine())
+	}
+	found, err := getOptionalRecord(stub, model.ApproverGroupTable, []string{group.GroupID}, nil)
+	if err != nil {
+		return fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+	}
+	if found {
+		return fmt.Errorf("The approver group %s already exists %s", group.GroupID, common.GetLine())
+	}
+
+	err = checkGroupMembers(stub, group)
+	if err != nil || !apply {
+		return err
+	}
+
+	now, err := hUtil.GetTxTime(stub)
+	if err != nil {
+		return fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+	}
+	group.CreatedAt = now.Format(time.RFC3339)
+	group.UpdatedAt = group.CreatedAt
+
+	common.Logger.Infof("Create ApproverGroup: %+v\n", group)
+	err = util.Createdata(stub, model.ApproverGroupTable, []string{group.GroupID}, group)
+	if err != nil { // Return error: Fail to insert data
+		return fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR5], err.Error(), common.GetLine())
+	}
+	return nil
+}
+
+// applyUpdateApproverGroup func to check the changes of an UpdateApproverGroup proposal and save them if apply is true
+func applyUpdateApproverGroup(stub shim.ChaincodeStubInterface, payload string, apply bool) error {
+	newGroup := new(model.ApproverGroup)
+	err := json.Unmarshal([]byte(payload), newGroup)
+	if err != nil { // Return error: Can't unmarshal json
+		return fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR3], err.Error(), common.GetLine())
+	}
+
+	group := new(model.ApproverGroup)
+	err = getRecord(stub, model.ApproverGroupTable, []string{newGroup.GroupID}, group)
+	if err != nil {
+		return fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+	}
+
+	err = applyUpdate(stub, payload, group, []string{"GroupID"}, approverGroupGovernanceRules)
+	if err != nil {
+		return err
+	}
+	err = checkGroupMembers(stub, group)
+	if err != nil || !apply {
+		return err
+	}
+
+	now, err := hUtil.GetTxTime(stub)
+	if err != nil {
+		return fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+	}
+	group.UpdatedAt = now.Format(time.RFC3339)
+
+	common.Logger.Infof("Update ApproverGroup: %+v\n", group)
+	err = util.Changeinfo(stub, model.ApproverGroupTable, []string{group.GroupID}, group)
+	if err != nil { // Return error: Fail to Update data
+		return fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR5], err.Error(), common.GetLine())
+	}
+	return nil
+}
+
+// checkGroupMembers func to check the group has members, each of them an enrolled SuperAdmin listed once
+func checkGroupMembers(stub shim.ChaincodeStubInterface, group *model.ApproverGroup) error {
+	if len(group.Members) == 0 {
+		return fmt.Errorf("The approver group %s has no members %s", group.GroupID, common.GetLine())
+	}
+
+	listed := map[string]bool{}
+	for _, member := range group.Members {
+		if listed[member] {
+			return fmt.Errorf("The SuperAdmin %s is listed twice %s", member, common.GetLine())
+		}
+		listed[member] = true
+
+		found, err := getOptionalRecord(stub, model.SuperAdminTable, []string{member}, nil)
+		if err != nil {
+			return fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+		}
+		if !found {
+			return fmt.Errorf("Unknown SuperAdmin %s %s", member, common.GetLine())
+		}
+	}
+	return nil
+}
+
+// checkPolicy func to parse the policy of a proposal, check the groups and SuperAdmins it names exist
+// and that it can't be satisfied by fewer SuperAdmins than the configured minimum quorum
+func checkPolicy(stub shim.ChaincodeStubInterface, expression string) error {
+	policy, err := hUtil.ParsePolicy(expression)
+	if err != nil {
+		return fmt.Errorf("%s %s", err.Error(), common.GetLine())
+	}
+
+	members, err := policyMembers(stub, policy)
+	if err != nil {
+		return err
+	}
+	var unreachable error
+	policy.Walk(func(node *hUtil.PolicyNode) {
+		if node.Kind == hUtil.PolicyGroup && node.N > len(members[node.ID]) && unreachable == nil {
+			unreachable = fmt.Errorf("Group(%d, '%s') can't be reached by %d members %s", node.N, node.ID, len(members[node.ID]), common.GetLine())
+		}
+	})
+	if unreachable != nil {
+		return unreachable
+	}
+
+	for _, superAdminID := range policy.SuperAdmins() {
+		found, err := getOptionalRecord(stub, model.SuperAdminTable, []string{superAdminID}, nil)
+		if err != nil {
+			return fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+		}
+		if !found {
+			return fmt.Errorf("Unknown SuperAdmin %s in the policy %s", superAdminID, common.GetLine())
+		}
+	}
+
+	config, err := loadConfig(stub)
+	if err != nil {
+		return fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+	}
+	min := policy.MinApprovers(members)
+	if min < config.MinQuorum {
+		return fmt.Errorf("The policy can be satisfied by %d SuperAdmins, the minimum quorum is %d %s", min, config.MinQuorum, common.GetLine())
+	}
+	return nil
+}
+
+// policySatisfied func to check whether the SuperAdmins who approved satisfy the policy, with the current members of its groups
+func policySatisfied(stub shim.ChaincodeStubInterface, expression string, approved map[string]bool) (bool, error) {
+	policy, err := hUtil.ParsePolicy(expression)
+	if err != nil {
+		return false, fmt.Errorf("%s %s", err.Error(), common.GetLine())
+	}
+
+	members, err := policyMembers(stub, policy)
+	if err != nil {
+		return false, err
+	}
+	return policy.Satisfied(approved, members), nil
+}
+
+// policyMembers func to get the members of each group the policy names, * being every active SuperAdmin
+func policyMembers(stub shim.ChaincodeStubInterface, policy *hUtil.PolicyNode) (map[string][]string, error) {
+	members := map[string][]string{}
+	for _, groupID := range policy.Groups() {
+		if groupID == model.AllSuperAdmins {
+			superAdmins, err := activeSuperAdminIDs(stub)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+			}
+			members[groupID] = superAdmins
+			continue
+		}
+
+		group := new(model.ApproverGroup)
+		found, err := getOptionalRecord(stub, model.ApproverGroupTable, []string{groupID}, group)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+		}
+		if !found {
+			return nil, fmt.Errorf("Unknown approver group %s in the policy %s", groupID, common.GetLine())
+		}
+		members[groupID] = group.Members
+	}
+	return members, nil
+}
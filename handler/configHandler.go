@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/Akachain/akc-go-sdk/common"
+	"github.com/Akachain/akc-go-sdk/util"
+	"github.com/Akachain/hstx-go-sdk/model"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// defaultConfig is used until a Config is stored on the ledger
+var defaultConfig = model.Config{
+	MaxProposalTTL:     7 * 24 * 60 * 60, // 7 days
+	DefaultProposalTTL: 24 * 60 * 60,     // 1 day
+	MinQuorum:          1,
+}
+
+// ConfigHandler ...
+type ConfigHandler struct{}
+
+// GetConfig ...
+func (ch *ConfigHandler) GetConfig(stub shim.ChaincodeStubInterface) (result *string, err error) {
+	config, err := loadConfig(stub)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+	}
+
+	bytes, err := json.Marshal(config)
+	if err != nil { // Return error: Can't marshal json
+		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR3], err.Error(), common.GetLine())
+	}
+	temp := ""
+	result = &temp
+	*result = string(bytes)
+
+	return result, nil
+}
+
+// applyUpdateConfig func to check the Config of an UpdateConfig proposal and store it if apply is true
+func applyUpdateConfig(stub shim.ChaincodeStubInterface, payload string, apply bool) error {
+	config := new(model.Config)
+	err := json.Unmarshal([]byte(payload), config)
+	if err != nil { // Return error: Can't unmarshal json
+		return fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR3], err.Error(), common.GetLine())
+	}
+
+	err = validateConfig(config)
+	if err != nil {
+		return err
+	}
+	err = checkQuorumReachable(stub, config.GovernanceQuorum, 0)
+	if err != nil {
+		return err
+	}
+	// Otherwise no proposal could be created
+	active, err := countActiveSuperAdmins(stub)
+	if err != nil {
+		return fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+	}
+	if config.MinQuorum > active {
+		return fmt.Errorf("The minimum quorum %d can't be reached by %d active SuperAdmins %s", config.MinQuorum, active, common.GetLine())
+	}
+	if !apply {
+		return nil
+	}
+
+	common.Logger.Infof("Update Config: %+v\n", config)
+	return saveConfig(stub, config)
+}
+
+// validateConfig func to check the values of a Config before it is saved
+func validateConfig(config *model.Config) error {
+	if config.MaxProposalTTL <= 0 || config.DefaultProposalTTL <= 0 {
+		return fmt.Errorf("The proposal TTLs must be positive %s", common.GetLine())
+	}
+	if config.DefaultProposalTTL > config.MaxProposalTTL {
+		return fmt.Errorf("DefaultProposalTTL can't be greater than MaxProposalTTL %s", common.GetLine())
+	}
+	if config.GovernanceQuorum < 0 {
+		return fmt.Errorf("The governance quorum can't be negative %s", common.GetLine())
+	}
+	if config.MinQuorum < 1 {
+		return fmt.Errorf("The minimum quorum must be positive %s", common.GetLine())
+	}
+	return nil
+}
+
+// loadConfig func to get the stored Config, or the default one if none has been stored
+func loadConfig(stub shim.ChaincodeStubInterface) (*model.Config, error) {
+	config := defaultConfig
+	_, err := getOptionalRecord(stub, model.ConfigTable, []string{model.ConfigID}, &config)
+	if err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// saveConfig func to store the Config
+func saveConfig(stub shim.ChaincodeStubInterface, config *model.Config) error {
+	err := util.UpdateExistingData(stub, model.ConfigTable, []string{model.ConfigID}, config)
+	if err != nil { // Return error: Fail to Update data
+		return fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR5], err.Error(), common.GetLine())
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Akachain/akc-go-sdk/common"
+	"github.com/Akachain/akc-go-sdk/util"
+	"github.com/Akachain/hstx-go-sdk/model"
+	hUtil "github.com/Akachain/hstx-go-sdk/utils"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// RevokeSuperAdminCredential revokes a credential of the caller's SuperAdmin, e.g. a lost YubiKey.
+// The credential can't sign approvals any more but it is kept for audits.
+func (sah *SuperAdminHandler) RevokeSuperAdminCredential(stub shim.ChaincodeStubInterface, superAdminID string, credentialID string) (result *string, err error) {
+	common.Logger.Debugf("Input-data sent to RevokeSuperAdminCredential func: %+v %+v\n", superAdminID, credentialID)
+
+	err = hUtil.IsSuperAdmin(stub)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s", err.Error(), common.GetLine())
+	}
+
+	// A SuperAdmin can only revoke its own credentials
+	superAdmin, err := getBoundSuperAdmin(stub, superAdminID)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s", err.Error(), common.GetLine())
+	}
+
+	credential := findCredential(superAdmin, credentialID)
+	if credential == nil {
+		return nil, fmt.Errorf("Unknown credential %s %s", credentialID, common.GetLine())
+	}
+	if credential.Status != model.CredentialActive {
+		return nil, fmt.Errorf("The credential %s is already %s %s", credentialID, credential.Status, common.GetLine())
+	}
+
+	now, err := hUtil.GetTxTime(stub)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+	}
+	credential.Status = model.CredentialRevoked
+	credential.RevokedAt = now.Format(time.RFC3339)
+
+	common.Logger.Infof("Revoke the credential %s of SuperAdmin %s\n", credentialID, superAdminID)
+	err = util.Changeinfo(stub, model.SuperAdminTable, []string{superAdmin.SuperAdminID}, superAdmin)
+	if err != nil { // Return error: Fail to Update data
+		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR5], err.Error(), common.GetLine())
+	}
+
+	bytes, err := json.Marshal(superAdmin)
+	if err != nil { // Return error: Can't marshal json
+		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR3], err.Error(), common.GetLine())
+	}
+	temp := ""
+	result = &temp
+	*result = string(bytes)
+
+	return result, nil
+}
+
+// applyAddSuperAdminCredential func to check the credential of an AddSuperAdminCredential proposal and add it if apply is true
+func applyAddSuperAdminCredential(stub shim.ChaincodeStubInterface, payload string, apply bool) error {
+	enrolment := new(model.CredentialEnrolment)
+	err := json.Unmarshal([]byte(payload), enrolment)
+	if err != nil { // Return error: Can't unmarshal json
+		return fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR3], err.Error(), common.GetLine())
+	}
+
+	superAdmin := new(model.SuperAdmin)
+	err = getRecord(stub, model.SuperAdminTable, []string{enrolment.SuperAdminID}, superAdmin)
+	if err != nil {
+		return fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+	}
+
+	now, err := hUtil.GetTxTime(stub)
+	if err != nil {
+		return fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+	}
+	credential := enrolment.Credential
+	err = checkCredential(&credential, now)
+	if err != nil {
+		return err
+	}
+	if findCredential(superAdmin, credential.CredentialID) != nil {
+		return fmt.Errorf("The credential %s already exists %s", credential.CredentialID, common.GetLine())
+	}
+	err = checkKeysNotEnrolled(stub, []string{credential.PublicKey})
+	if err != nil || !apply {
+		return err
+	}
+
+	superAdmin.Credentials = append(superAdmin.Credentials, credential)
+	common.Logger.Infof("Add the credential %s to SuperAdmin %s\n", credential.CredentialID, superAdmin.SuperAdminID)
+	err = util.Changeinfo(stub, model.SuperAdminTable, []string{superAdmin.SuperAdminID}, superAdmin)
+	if err != nil { // Return error: Fail to Update data
+		return fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR5], err.Error(), common.GetLine())
+	}
+	return nil
+}
+
+// checkCredential func to check a new credential and fill its defaults
+func checkCredential(credential *model.Credential, now time.Time) error {
+	if len(credential.CredentialID) == 0 {
+		return fmt.Errorf("%s %s", "The CredentialID can't be empty", common.GetLine())
+	}
+
+	scheme, hashAlgorithm, err := hUtil.ResolveSignatureScheme(credential.PublicKey, credential.SignatureScheme, credential.HashAlgorithm)
+	if err != nil {
+		return fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR6], err.Error(), common.GetLine())
+	}
+	credential.SignatureScheme = scheme
+	credential.HashAlgorithm = hashAlgorithm
+	credential.Status = model.CredentialActive
+	credential.SignCount = 0
+	credential.AddedAt = now.UTC().Format(time.RFC3339)
+	credential.RevokedAt = ""
+	return nil
+}
+
+// findCredential func to get the SuperAdmin's credential with this ID, nil if there is none
+func findCredential(superAdmin *model.SuperAdmin, credentialID string) *model.Credential {
+	for i := range superAdmin.Credentials {
+		if superAdmin.Credentials[i].CredentialID == credentialID {
+			return &superAdmin.Credentials[i]
+		}
+	}
+	return nil
+}
+
+// signingCredential func to get the key an approval is signed with: the SuperAdmin's PublicKey if credentialID is empty,
+// or one of its active credentials. The PublicKey is returned as a copy, the credentials in place.
+func signingCredential(superAdmin *model.SuperAdmin, credentialID string) (*model.Credential, error) {
+	if len(credentialID) == 0 {
+		return &model.Credential{
+			PublicKey:       superAdmin.PublicKey,
+			SignatureScheme: superAdmin.SignatureScheme,
+			HashAlgorithm:   superAdmin.HashAlgorithm,
+			Status:          model.CredentialActive,
+			SignCount:       superAdmin.SignCount,
+		}, nil
+	}
+
+	credential := findCredential(superAdmin, credentialID)
+	if credential == nil {
+		return nil, fmt.Errorf("Unknown credential %s %s", credentialID, common.GetLine())
+	}
+	if credential.Status != model.CredentialActive {
+		return nil, fmt.Errorf("The credential %s is %s %s", credentialID, credential.Status, common.GetLine())
+	}
+	return credential, nil
+}
+
+// superAdminKeys func to list every key enrolled for the SuperAdmin: its current and previous keys and its credentials
+func superAdminKeys(superAdmin *model.SuperAdmin) []string {
+	keys := []string{}
+	for _, key := range keyHistory(superAdmin) {
+		keys = append(keys, key.PublicKey)
+	}
+	for _, credential := range superAdmin.Credentials {
+		keys = append(keys, credential.PublicKey)
+	}
+	return keys
+}
+
+// keyFingerprints func to get the fingerprints of the keys, it fails if a key is listed twice
+func keyFingerprints(publicKeys []string) (map[string]bool, error) {
+	fingerprints := map[string]bool{}
+	for _, publicKey := range publicKeys {
+		fingerprint, err := hUtil.PublicKeyFingerprint(publicKey)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR6], err.Error(), common.GetLine())
+		}
+		if fingerprints[fingerprint] {
+			return nil, fmt.Errorf("%s %s", "The same key is enrolled twice", common.GetLine())
+		}
+		fingerprints[fingerprint] = true
+	}
+	return fingerprints, nil
+}
+
+// checkKeysNotEnrolled func to check none of the keys is already enrolled for a SuperAdmin,
+// so a person can't approve twice under two SuperAdminIDs
+func checkKeysNotEnrolled(stub shim.ChaincodeStubInterface, publicKeys []string) error {
+	fingerprints, err := keyFingerprints(publicKeys)
+	if err != nil {
+		return err
+	}
+
+	resIterator, err := stub.GetStateByPartialCompositeKey(model.SuperAdminTable, []string{})
+	if err != nil {
+		return fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+	}
+	defer resIterator.Close()
+
+	for resIterator.HasNext() {
+		state, err := resIterator.Next()
+		if err != nil {
+			return fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+		}
+		superAdmin := new(model.SuperAdmin)
+		err = json.Unmarshal(state.Value, superAdmin)
+		if err != nil { // Convert JSON error
+			return fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR3], err.Error(), common.GetLine())
+		}
+
+		for _, publicKey := range superAdminKeys(superAdmin) {
+			// Keys enrolled before they were checked may not parse, they can't match a valid one
+			fingerprint, err := hUtil.PublicKeyFingerprint(publicKey)
+			if err == nil && fingerprints[fingerprint] {
+				return fmt.Errorf("The key is already enrolled for SuperAdmin %s %s", superAdmin.SuperAdminID, common.GetLine())
+			}
+		}
+	}
+	return nil
+}
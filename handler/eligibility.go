@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/Akachain/akc-go-sdk/common"
+	"github.com/Akachain/hstx-go-sdk/model"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// isRestricted func to check whether the proposal names who can approve it
+func isRestricted(proposal *model.Proposal) bool {
+	return len(proposal.EligibleApprovers) > 0 || len(proposal.EligibleGroups) > 0
+}
+
+// checkEligibility func to check the SuperAdmins and the approver groups the proposal names exist
+func checkEligibility(stub shim.ChaincodeStubInterface, proposal *model.Proposal) error {
+	for _, superAdminID := range proposal.EligibleApprovers {
+		found, err := getOptionalRecord(stub, model.SuperAdminTable, []string{superAdminID}, nil)
+		if err != nil {
+			return fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+		}
+		if !found {
+			return fmt.Errorf("Unknown eligible approver %s %s", superAdminID, common.GetLine())
+		}
+	}
+	for _, groupID := range proposal.EligibleGroups {
+		found, err := getOptionalRecord(stub, model.ApproverGroupTable, []string{groupID}, nil)
+		if err != nil {
+			return fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+		}
+		if !found {
+			return fmt.Errorf("Unknown eligible group %s %s", groupID, common.GetLine())
+		}
+	}
+	return nil
+}
+
+// isEligible func to check whether the SuperAdmin can vote on the proposal: any SuperAdmin if the proposal isn't
+// restricted, else one of its EligibleApprovers or a current member of its EligibleGroups
+func isEligible(stub shim.ChaincodeStubInterface, proposal *model.Proposal, superAdminID string) (bool, error) {
+	if !isRestricted(proposal) {
+		return true, nil
+	}
+	eligible, err := eligibleIDs(stub, proposal)
+	if err != nil {
+		return false, err
+	}
+	return eligible[superAdminID], nil
+}
+
+// eligibleApprovers func to list the active SuperAdmins who can vote on the proposal
+func eligibleApprovers(stub shim.ChaincodeStubInterface, proposal *model.Proposal) ([]*model.SuperAdmin, error) {
+	superAdmins, err := activeSuperAdmins(stub)
+	if err != nil || !isRestricted(proposal) {
+		return superAdmins, err
+	}
+
+	eligible, err := eligibleIDs(stub, proposal)
+	if err != nil {
+		return nil, err
+	}
+	approvers := []*model.SuperAdmin{}
+	for _, superAdmin := range superAdmins {
+		if eligible[superAdmin.SuperAdminID] {
+			approvers = append(approvers, superAdmin)
+		}
+	}
+	return approvers, nil
+}
+
+// eligibleIDs func to get the IDs of the EligibleApprovers and the members of the EligibleGroups of the proposal
+func eligibleIDs(stub shim.ChaincodeStubInterface, proposal *model.Proposal) (map[string]bool, error) {
+	eligible := map[string]bool{}
+	for _, superAdminID := range proposal.EligibleApprovers {
+		eligible[superAdminID] = true
+	}
+	for _, groupID := range proposal.EligibleGroups {
+		group := new(model.ApproverGroup)
+		err := getRecord(stub, model.ApproverGroupTable, []string{groupID}, group)
+		if err != nil {
+			return nil, err
+		}
+		for _, member := range group.Members {
+			eligible[member] = true
+		}
+	}
+	return eligible, nil
+}
@@ -0,0 +1,298 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/Akachain/akc-go-sdk/common"
+	"github.com/Akachain/akc-go-sdk/util"
+	"github.com/Akachain/hstx-go-sdk/model"
+	hUtil "github.com/Akachain/hstx-go-sdk/utils"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// governanceAppliers checks the payload of each type of governance proposal and, if apply is true, makes the change
+var governanceAppliers = map[string]func(stub shim.ChaincodeStubInterface, payload string, apply bool) error{
+	model.ProposalAddSuperAdmin:           applyAddSuperAdmin,
+	model.ProposalUpdateSuperAdmin:        applyUpdateSuperAdmin,
+	model.ProposalRotateSuperAdminKey:     applyRotateSuperAdminKey,
+	model.ProposalAddSuperAdminCredential: applyAddSuperAdminCredential,
+	model.ProposalCreateApproverGroup:     applyCreateApproverGroup,
+	model.ProposalUpdateApproverGroup:     applyUpdateApproverGroup,
+	model.ProposalUpdateConfig:            applyUpdateConfig,
+}
+
+// GovernanceHandler ...
+type GovernanceHandler struct{}
+
+// Bootstrap enrols the genesis SuperAdmins and sets the governance quorum.
+// It only succeeds on a ledger without SuperAdmins, afterwards they change through governance proposals.
+func (gh *GovernanceHandler) Bootstrap(stub shim.ChaincodeStubInterface, genesisStr string) error {
+	common.Logger.Debugf("Input-data sent to Bootstrap func: %+v\n", genesisStr)
+
+	genesis := new(model.Genesis)
+	err := json.Unmarshal([]byte(genesisStr), genesis)
+	if err != nil { // Return error: Can't unmarshal json
+		return fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR3], err.Error(), common.GetLine())
+	}
+
+	superAdmins, err := stub.GetStateByPartialCompositeKey(model.SuperAdminTable, []string{})
+	if err != nil {
+		return fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+	}
+	defer superAdmins.Close()
+	if superAdmins.HasNext() {
+		return fmt.Errorf("%s %s", "The SuperAdmins have already been enrolled", common.GetLine())
+	}
+
+	if len(genesis.SuperAdmins) == 0 {
+		return fmt.Errorf("%s %s", "The genesis must contain at least one SuperAdmin", common.GetLine())
+	}
+	// A transaction doesn't read its own writes, so duplicates are found here rather than by Createdata
+	enrolled := map[string]bool{}
+	keys := []string{}
+	active := 0
+	for i := range genesis.SuperAdmins {
+		superAdmin := &genesis.SuperAdmins[i]
+		if enrolled[superAdmin.SuperAdminID] {
+			return fmt.Errorf("The SuperAdmin %s is enrolled twice %s", superAdmin.SuperAdminID, common.GetLine())
+		}
+		enrolled[superAdmin.SuperAdminID] = true
+
+		err = checkNewSuperAdmin(stub, superAdmin)
+		if err != nil {
+			return fmt.Errorf("%s %s", err.Error(), common.GetLine())
+		}
+		keys = append(keys, superAdminKeys(superAdmin)...)
+		if isActiveSuperAdmin(superAdmin) {
+			active++
+		}
+	}
+	_, err = keyFingerprints(keys)
+	if err != nil {
+		return fmt.Errorf("%s %s", err.Error(), common.GetLine())
+	}
+	if genesis.GovernanceQuorum < 1 || genesis.GovernanceQuorum > active {
+		return fmt.Errorf("The governance quorum must be between 1 and %d %s", active, common.GetLine())
+	}
+
+	for i := range genesis.SuperAdmins {
+		superAdmin := &genesis.SuperAdmins[i]
+		common.Logger.Infof("Create SuperAdmin: %+v\n", superAdmin)
+		err = util.Createdata(stub, model.SuperAdminTable, []string{superAdmin.SuperAdminID}, superAdmin)
+		if err != nil { // Return error: Fail to insert data
+			return fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR5], err.Error(), common.GetLine())
+		}
+	}
+
+	config, err := loadConfig(stub)
+	if err != nil {
+		return fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+	}
+	config.GovernanceQuorum = genesis.GovernanceQuorum
+	return saveConfig(stub, config)
+}
+
+// BindSuperAdmins binds the SuperAdmins created before the identity binding, who can't act until then, to their
+// MSPID and CertID. Like Bootstrap it runs in Init, and only once for each SuperAdmin: a bound SuperAdmin is
+// rebound with an UpdateSuperAdmin governance proposal.
+func (gh *GovernanceHandler) BindSuperAdmins(stub shim.ChaincodeStubInterface, bindingsStr string) error {
+	common.Logger.Debugf("Input-data sent to BindSuperAdmins func: %+v\n", bindingsStr)
+
+	bindings := []model.SuperAdminBinding{}
+	err := json.Unmarshal([]byte(bindingsStr), &bindings)
+	if err != nil { // Return error: Can't unmarshal json
+		return fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR3], err.Error(), common.GetLine())
+	}
+	if len(bindings) == 0 {
+		return fmt.Errorf("%s %s", "The bindings must contain at least one SuperAdmin", common.GetLine())
+	}
+
+	bound := map[string]bool{}
+	for _, binding := range bindings {
+		if bound[binding.SuperAdminID] {
+			return fmt.Errorf("The SuperAdmin %s is bound twice %s", binding.SuperAdminID, common.GetLine())
+		}
+		bound[binding.SuperAdminID] = true
+		if len(binding.MSPID) == 0 || len(binding.CertID) == 0 {
+			return fmt.Errorf("%s %s", "MSPID and CertID can't be empty", common.GetLine())
+		}
+
+		superAdmin := new(model.SuperAdmin)
+		err = getRecord(stub, model.SuperAdminTable, []string{binding.SuperAdminID}, superAdmin)
+		if err != nil {
+			return fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+		}
+		if len(superAdmin.MSPID) > 0 || len(superAdmin.CertID) > 0 {
+			return fmt.Errorf("The SuperAdmin %s is already bound, it is rebound with an UpdateSuperAdmin governance proposal %s", binding.SuperAdminID, common.GetLine())
+		}
+
+		superAdmin.MSPID = binding.MSPID
+		superAdmin.CertID = binding.CertID
+		common.Logger.Infof("Bind SuperAdmin: %+v\n", superAdmin)
+		err = util.Changeinfo(stub, model.SuperAdminTable, []string{superAdmin.SuperAdminID}, superAdmin)
+		if err != nil { // Return error: Fail to update data
+			return fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR5], err.Error(), common.GetLine())
+		}
+	}
+	return nil
+}
+
+// CreateGovernanceProposal creates a proposal to add, change or re-key a SuperAdmin or to change the Config.
+// It needs the governance quorum of approvals and takes effect when it is committed.
+func (gh *GovernanceHandler) CreateGovernanceProposal(stub shim.ChaincodeStubInterface, proposalStr string) (result *string, err error) {
+	common.Logger.Debugf("Input-data sent to CreateGovernanceProposal func: %+v\n", proposalStr)
+
+	proposal := new(model.Proposal)
+	err = json.Unmarshal([]byte(proposalStr), proposal)
+	if err != nil { // Return error: Can't unmarshal json
+		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR3], err.Error(), common.GetLine())
+	}
+
+	// Check role: SuperAdmin
+	err = hUtil.IsSuperAdmin(stub)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s", err.Error(), common.GetLine())
+	}
+
+	// The caller must be the active SuperAdmin named in CreatedBy
+	superAdmin, err := getBoundSuperAdmin(stub, proposal.CreatedBy)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s", err.Error(), common.GetLine())
+	}
+	if !isActiveSuperAdmin(superAdmin) {
+		return nil, fmt.Errorf("%s %s", "This SuperAdmin is not active", common.GetLine())
+	}
+
+	if !isGovernanceProposal(proposal) {
+		return nil, fmt.Errorf("Unknown governance proposal type %s %s", proposal.Type, common.GetLine())
+	}
+	// Reject a change which couldn't be applied as early as possible, it is checked again at commit
+	err = applyGovernance(stub, proposal, false)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s", err.Error(), common.GetLine())
+	}
+
+	// The creator can't choose the number of approvals
+	config, err := loadConfig(stub)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+	}
+	proposal.QuorumNumber, err = governanceQuorum(stub, config)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+	}
+	proposal.ThresholdWeight = 0
+	proposal.Policy = ""
+	proposal.RejectionRule = ""
+	proposal.RejectionCount = 0
+	proposal.EligibleApprovers = nil
+	proposal.EligibleGroups = nil
+
+	return createProposal(stub, proposal)
+}
+
+// isGovernanceProposal func to check whether the proposal changes the SuperAdmins or the Config
+func isGovernanceProposal(proposal *model.Proposal) bool {
+	_, ok := governanceAppliers[proposal.Type]
+	return ok
+}
+
+// applyGovernance func to check the change of a governance proposal and make it if apply is true
+func applyGovernance(stub shim.ChaincodeStubInterface, proposal *model.Proposal, apply bool) error {
+	applier, ok := governanceAppliers[proposal.Type]
+	if !ok {
+		return fmt.Errorf("Unknown governance proposal type %s %s", proposal.Type, common.GetLine())
+	}
+	return applier(stub, proposal.Payload, apply)
+}
+
+// governanceQuorum func to get the number of approvals a governance proposal needs
+func governanceQuorum(stub shim.ChaincodeStubInterface, config *model.Config) (int, error) {
+	if config.GovernanceQuorum > 0 {
+		return config.GovernanceQuorum, nil
+	}
+
+	// Ledgers created before Init took a genesis use a majority of the active SuperAdmins
+	active, err := countActiveSuperAdmins(stub)
+	if err != nil {
+		return 0, err
+	}
+	return active/2 + 1, nil
+}
+
+// checkGovernanceQuorum func to check the stored governance quorum can still be reached
+// once the number of active SuperAdmins has changed by delta
+func checkGovernanceQuorum(stub shim.ChaincodeStubInterface, delta int) error {
+	config, err := loadConfig(stub)
+	if err != nil {
+		return fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+	}
+	return checkQuorumReachable(stub, config.GovernanceQuorum, delta)
+}
+
+// checkQuorumReachable func to check there are enough active SuperAdmins to reach the governance quorum,
+// once their number has changed by delta
+func checkQuorumReachable(stub shim.ChaincodeStubInterface, quorum int, delta int) error {
+	active, err := countActiveSuperAdmins(stub)
+	if err != nil {
+		return fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+	}
+	active += delta
+
+	if quorum == 0 {
+		quorum = 1
+	}
+	if quorum > active {
+		return fmt.Errorf("The governance quorum %d can't be reached by %d active SuperAdmins %s", quorum, active, common.GetLine())
+	}
+	return nil
+}
+
+// countActiveSuperAdmins func to count the SuperAdmins who can approve proposals
+func countActiveSuperAdmins(stub shim.ChaincodeStubInterface) (int, error) {
+	superAdmins, err := activeSuperAdminIDs(stub)
+	if err != nil {
+		return 0, err
+	}
+	return len(superAdmins), nil
+}
+
+// activeSuperAdminIDs func to list the IDs of the SuperAdmins who can approve proposals
+func activeSuperAdminIDs(stub shim.ChaincodeStubInterface) ([]string, error) {
+	superAdmins, err := activeSuperAdmins(stub)
+	if err != nil {
+		return nil, err
+	}
+	superAdminIDs := []string{}
+	for _, superAdmin := range superAdmins {
+		superAdminIDs = append(superAdminIDs, superAdmin.SuperAdminID)
+	}
+	return superAdminIDs, nil
+}
+
+// activeSuperAdmins func to list the SuperAdmins who can approve proposals
+func activeSuperAdmins(stub shim.ChaincodeStubInterface) ([]*model.SuperAdmin, error) {
+	resIterator, err := stub.GetStateByPartialCompositeKey(model.SuperAdminTable, []string{})
+	if err != nil {
+		return nil, err
+	}
+	defer resIterator.Close()
+
+	superAdmins := []*model.SuperAdmin{}
+	for resIterator.HasNext() {
+		state, err := resIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		superAdmin := new(model.SuperAdmin)
+		err = json.Unmarshal(state.Value, superAdmin)
+		if err != nil { // Convert JSON error
+			return nil, err
+		}
+		if isActiveSuperAdmin(superAdmin) {
+			superAdmins = append(superAdmins, superAdmin)
+		}
+	}
+	return superAdmins, nil
+}
@@ -1,11 +1,26 @@
 package handler
 
+import (
+	"github.com/Akachain/akc-go-sdk/util"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
 // Handler ...
 type Handler struct {
-	SuperAdminHandler *SuperAdminHandler
-	AdminHandler      *AdminHandler
-	ProposalHandler   *ProposalHandler
-	ApprovalHandler   *ApprovalHandler
+	SuperAdminHandler    *SuperAdminHandler
+	AdminHandler         *AdminHandler
+	ProposalHandler      *ProposalHandler
+	ApprovalHandler      *ApprovalHandler
+	ConfigHandler        *ConfigHandler
+	GovernanceHandler    *GovernanceHandler
+	ApproverGroupHandler *ApproverGroupHandler
+}
+
+// NewHandler returns an initialized Handler
+func NewHandler() *Handler {
+	h := new(Handler)
+	h.InitHandler()
+	return h
 }
 
 // InitHandler ...
@@ -14,4 +29,18 @@ func (h *Handler) InitHandler() {
 	h.AdminHandler = new(AdminHandler)
 	h.ProposalHandler = new(ProposalHandler)
 	h.ApprovalHandler = new(ApprovalHandler)
+	h.ConfigHandler = new(ConfigHandler)
+	h.GovernanceHandler = new(GovernanceHandler)
+	h.ApproverGroupHandler = new(ApproverGroupHandler)
+}
+
+// getRecord loads the row stored under keys in table into record, a pointer to a model struct
+func getRecord(stub shim.ChaincodeStubInterface, table string, keys []string, record interface{}) error {
+	_, err := util.GetTableRow(stub, table, keys, record, util.FAIL_IF_MISSING)
+	return err
+}
+
+// getOptionalRecord loads the row stored under keys in table into record if it exists
+func getOptionalRecord(stub shim.ChaincodeStubInterface, table string, keys []string, record interface{}) (bool, error) {
+	return util.GetTableRow(stub, table, keys, record, util.DONT_FAIL_IF_MISSING)
 }
@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/Akachain/akc-go-sdk/common"
+	"github.com/Akachain/hstx-go-sdk/model"
+	hUtil "github.com/Akachain/hstx-go-sdk/utils"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// getBoundSuperAdmin func to get a SuperAdmin and check the caller is the identity bound to it
+func getBoundSuperAdmin(stub shim.ChaincodeStubInterface, superAdminID string) (*model.SuperAdmin, error) {
+	superAdmin := new(model.SuperAdmin)
+	err := getRecord(stub, model.SuperAdminTable, []string{superAdminID}, superAdmin)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+	}
+
+	err = hUtil.IsInvoker(stub, superAdmin.MSPID, superAdmin.CertID)
+	if err != nil {
+		return nil, fmt.Errorf("The caller can't act as SuperAdmin %s. Cause: %s %s", superAdminID, err.Error(), common.GetLine())
+	}
+	return superAdmin, nil
+}
+
+// getBoundAdmin func to get an Admin and check the caller is the identity bound to it
+func getBoundAdmin(stub shim.ChaincodeStubInterface, adminID string) (*model.Admin, error) {
+	admin := new(model.Admin)
+	err := getRecord(stub, model.AdminTable, []string{adminID}, admin)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+	}
+
+	err = hUtil.IsInvoker(stub, admin.MSPID, admin.CertID)
+	if err != nil {
+		return nil, fmt.Errorf("The caller can't act as Admin %s. Cause: %s %s", adminID, err.Error(), common.GetLine())
+	}
+	return admin, nil
+}
@@ -0,0 +1,202 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/Akachain/akc-go-sdk/common"
+	"github.com/Akachain/hstx-go-sdk/model"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// Secondary indexes are composite keys written along with the rows they point to. A lookup is a scan of
+// the keys, so it works on LevelDB and, unlike a rich query, is re-validated when the transaction commits.
+
+// indexValue is stored under the keys of the secondary indexes, an empty value would delete the key
+var indexValue = []byte{0x00}
+
+// putIndexEntry func to add an entry to a secondary index
+func putIndexEntry(stub shim.ChaincodeStubInterface, index string, keys ...string) error {
+	key, err := stub.CreateCompositeKey(index, keys)
+	if err != nil {
+		return err
+	}
+	return stub.PutState(key, indexValue)
+}
+
+// deleteIndexEntry func to remove an entry from a secondary index
+func deleteIndexEntry(stub shim.ChaincodeStubInterface, index string, keys ...string) error {
+	key, err := stub.CreateCompositeKey(index, keys)
+	if err != nil {
+		return err
+	}
+	return stub.DelState(key)
+}
+
+// scanIndex func to get the keys which follow prefix in the entries of a secondary index, in key order.
+// fn is called with each of them until it returns false.
+func scanIndex(stub shim.ChaincodeStubInterface, index string, prefix []string, fn func(keys []string) bool) error {
+	iterator, err := stub.GetStateByPartialCompositeKey(index, prefix)
+	if err != nil {
+		return err
+	}
+	defer iterator.Close()
+	for iterator.HasNext() {
+		entry, err := iterator.Next()
+		if err != nil {
+			return err
+		}
+		_, keys, err := stub.SplitCompositeKey(entry.Key)
+		if err != nil {
+			return err
+		}
+		if !fn(keys[len(prefix):]) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// indexProposal func to move the proposal's entries of the secondary indexes from status from to its current status.
+// A new proposal, whose status was empty, is added to the creator index too.
+func indexProposal(stub shim.ChaincodeStubInterface, proposal *model.Proposal, from model.ProposalStatus) error {
+	if len(from) > 0 {
+		err := deleteIndexEntry(stub, model.ProposalStatusIndex, string(from), proposal.CreatedAt, proposal.ProposalID)
+		if err != nil {
+			return err
+		}
+	} else {
+		err := putIndexEntry(stub, model.ProposalCreatorIndex, proposal.CreatedBy, proposal.CreatedAt, proposal.ProposalID)
+		if err != nil {
+			return err
+		}
+	}
+	err := putIndexEntry(stub, model.ProposalStatusIndex, string(proposal.Status), proposal.CreatedAt, proposal.ProposalID)
+	if err != nil || len(proposal.ExpiresAt) == 0 {
+		return err
+	}
+
+	// Only the proposals which can still expire are in the expiry index
+	if proposal.Status == model.ProposalPending || proposal.Status == model.ProposalApproved {
+		return putIndexEntry(stub, model.ProposalExpiryIndex, proposal.ExpiresAt, proposal.ProposalID)
+	}
+	return deleteIndexEntry(stub, model.ProposalExpiryIndex, proposal.ExpiresAt, proposal.ProposalID)
+}
+
+// indexApproval func to add the approval to the secondary indexes, its indexed fields can't change
+func indexApproval(stub shim.ChaincodeStubInterface, approval *model.Approval) error {
+	err := putIndexEntry(stub, model.ApprovalIDIndex, approval.ApprovalID, approval.ProposalID, approval.ApproverID)
+	if err != nil {
+		return err
+	}
+	return putIndexEntry(stub, model.ApprovalApproverIndex, approval.ApproverID, approval.ProposalID)
+}
+
+// getProposalsByStatus func to get the proposals with one of the statuses, through the status index
+func getProposalsByStatus(stub shim.ChaincodeStubInterface, statuses ...model.ProposalStatus) ([]*model.Proposal, error) {
+	proposals := []*model.Proposal{}
+	for _, status := range statuses {
+		proposalIDs := []string{}
+		err := scanIndex(stub, model.ProposalStatusIndex, []string{string(status)}, func(keys []string) bool {
+			proposalIDs = append(proposalIDs, keys[1])
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, proposalID := range proposalIDs {
+			proposal := new(model.Proposal)
+			err = getRecord(stub, model.ProposalTable, []string{proposalID}, proposal)
+			if err != nil {
+				return nil, err
+			}
+			proposals = append(proposals, proposal)
+		}
+	}
+	return proposals, nil
+}
+
+// RebuildIndexes writes the secondary index entries of at most batchSize proposals or approvals, from startKey,
+// e.g. of the ones stored before the indexes existed. Writing an entry again doesn't change it. It is called
+// with an empty startKey first, then with the returned NextStartKey until it is empty.
+func (sah *ProposalHandler) RebuildIndexes(stub shim.ChaincodeStubInterface, batchSizeStr string, startKey string) (result *string, err error) {
+	common.Logger.Debugf("Input-data sent to RebuildIndexes func: %+v %+v\n", batchSizeStr, startKey)
+
+	batchSize, err := strconv.Atoi(batchSizeStr)
+	if err != nil || batchSize <= 0 {
+		return nil, fmt.Errorf("%s %s", "The batch size must be a positive integer", common.GetLine())
+	}
+
+	// The proposals are indexed first, then the approvals. The start key is a key of their tables.
+	table := model.ProposalTable
+	if len(startKey) > 0 {
+		table, _, err = stub.SplitCompositeKey(startKey)
+		if err != nil || (table != model.ProposalTable && table != model.ApprovalTable) {
+			return nil, fmt.Errorf("%s %s", "The start key isn't a key of the proposals or approvals", common.GetLine())
+		}
+	}
+
+	// Fabric doesn't allow writes after a paginated query, nor a range query over composite keys, so the whole
+	// table is scanned and the rows before startKey are skipped
+	rows, err := stub.GetStateByPartialCompositeKey(table, []string{})
+	if err != nil {
+		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+	}
+	defer rows.Close()
+
+	rebuild := model.IndexRebuild{}
+	for rows.HasNext() {
+		row, err := rows.Next()
+		if err != nil {
+			return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+		}
+		if row.Key < startKey {
+			continue
+		}
+		if rebuild.Proposals+rebuild.Approvals == batchSize {
+			rebuild.NextStartKey = row.Key
+			break
+		}
+
+		if table == model.ProposalTable {
+			proposal := new(model.Proposal)
+			err = json.Unmarshal(row.Value, proposal)
+			if err != nil { // Convert JSON error
+				return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR3], err.Error(), common.GetLine())
+			}
+			err = indexProposal(stub, proposal, "")
+			rebuild.Proposals++
+		} else {
+			approval := new(model.Approval)
+			err = json.Unmarshal(row.Value, approval)
+			if err != nil { // Convert JSON error
+				return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR3], err.Error(), common.GetLine())
+			}
+			err = indexApproval(stub, approval)
+			rebuild.Approvals++
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR5], err.Error(), common.GetLine())
+		}
+	}
+
+	if len(rebuild.NextStartKey) == 0 && table == model.ProposalTable {
+		// The approvals follow the last proposal, starting at the key of their table
+		rebuild.NextStartKey, err = stub.CreateCompositeKey(model.ApprovalTable, []string{})
+		if err != nil {
+			return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+		}
+	}
+
+	bytes, err := json.Marshal(rebuild)
+	if err != nil { // Return error: Can't marshal json
+		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR3], err.Error(), common.GetLine())
+	}
+	temp := ""
+	result = &temp
+	*result = string(bytes)
+
+	return result, nil
+}
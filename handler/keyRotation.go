@@ -0,0 +1,258 @@
+package handler
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Akachain/akc-go-sdk/common"
+	"github.com/Akachain/akc-go-sdk/util"
+	"github.com/Akachain/hstx-go-sdk/model"
+	hUtil "github.com/Akachain/hstx-go-sdk/utils"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// RotateSuperAdminKey replaces the key of a SuperAdmin by a new version. The rotation must be signed by the current key,
+// a SuperAdmin who lost it needs a RotateSuperAdminKey governance proposal instead.
+func (sah *SuperAdminHandler) RotateSuperAdminKey(stub shim.ChaincodeStubInterface, rotationStr string) (result *string, err error) {
+	common.Logger.Debugf("Input-data sent to RotateSuperAdminKey func: %+v\n", rotationStr)
+
+	err = hUtil.IsSuperAdmin(stub)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s", err.Error(), common.GetLine())
+	}
+
+	rotation := new(model.KeyRotation)
+	err = json.Unmarshal([]byte(rotationStr), rotation)
+	if err != nil { // Return error: Can't unmarshal json
+		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR3], err.Error(), common.GetLine())
+	}
+
+	// A SuperAdmin can only rotate its own key
+	superAdmin, err := getBoundSuperAdmin(stub, rotation.SuperAdminID)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s", err.Error(), common.GetLine())
+	}
+	if !isActiveSuperAdmin(superAdmin) {
+		return nil, fmt.Errorf("%s %s", "This SuperAdmin is not active", common.GetLine())
+	}
+
+	// The current key hands over to the new one
+	payload, err := keyRotationPayload(superAdmin, rotation)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s", err.Error(), common.GetLine())
+	}
+	err = hUtil.VerifySignature(superAdmin.PublicKey, superAdmin.SignatureScheme, superAdmin.HashAlgorithm, payload, rotation.Signature)
+	if err != nil { // Return error: Verify error
+		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR8], err.Error(), common.GetLine())
+	}
+
+	now, err := hUtil.GetTxTime(stub)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+	}
+	err = rotateKey(stub, superAdmin, rotation, now)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s", err.Error(), common.GetLine())
+	}
+
+	common.Logger.Infof("Rotate the key of SuperAdmin %s to version %d\n", superAdmin.SuperAdminID, superAdmin.KeyVersion)
+	err = util.Changeinfo(stub, model.SuperAdminTable, []string{superAdmin.SuperAdminID}, superAdmin)
+	if err != nil { // Return error: Fail to Update data
+		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR5], err.Error(), common.GetLine())
+	}
+
+	bytes, err := json.Marshal(superAdmin)
+	if err != nil { // Return error: Can't marshal json
+		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR3], err.Error(), common.GetLine())
+	}
+	temp := ""
+	result = &temp
+	*result = string(bytes)
+
+	return result, nil
+}
+
+// GetKeyRotationPayload returns the payload the current key of a SuperAdmin must sign to rotate to the new key
+func (sah *SuperAdminHandler) GetKeyRotationPayload(stub shim.ChaincodeStubInterface, rotationStr string) (result *string, err error) {
+	common.Logger.Debugf("Input-data sent to GetKeyRotationPayload func: %+v\n", rotationStr)
+
+	rotation := new(model.KeyRotation)
+	err = json.Unmarshal([]byte(rotationStr), rotation)
+	if err != nil { // Return error: Can't unmarshal json
+		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR3], err.Error(), common.GetLine())
+	}
+
+	superAdmin := new(model.SuperAdmin)
+	err = getRecord(stub, model.SuperAdminTable, []string{rotation.SuperAdminID}, superAdmin)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+	}
+
+	payload, err := keyRotationPayload(superAdmin, rotation)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s", err.Error(), common.GetLine())
+	}
+
+	// The current key signs, so the digest is made with its hash algorithm. Ed25519 signs the message itself.
+	_, hashAlgorithm, err := hUtil.ResolveSignatureScheme(superAdmin.PublicKey, superAdmin.SignatureScheme, superAdmin.HashAlgorithm)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s", err.Error(), common.GetLine())
+	}
+	digest, err := hUtil.Digest(hashAlgorithm, payload)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s", err.Error(), common.GetLine())
+	}
+	response := map[string]string{"Message": base64.StdEncoding.EncodeToString(payload)}
+	if digest != nil {
+		response["Digest"] = base64.StdEncoding.EncodeToString(digest)
+		response["HashAlgorithm"] = hashAlgorithm
+	}
+
+	bytes, err := json.Marshal(response)
+	if err != nil { // Return error: Can't marshal json
+		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR3], err.Error(), common.GetLine())
+	}
+	temp := ""
+	result = &temp
+	*result = string(bytes)
+
+	return result, nil
+}
+
+// applyRotateSuperAdminKey func to check the rotation of a RotateSuperAdminKey proposal and save it if apply is true
+func applyRotateSuperAdminKey(stub shim.ChaincodeStubInterface, payload string, apply bool) error {
+	rotation := new(model.KeyRotation)
+	err := json.Unmarshal([]byte(payload), rotation)
+	if err != nil { // Return error: Can't unmarshal json
+		return fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR3], err.Error(), common.GetLine())
+	}
+
+	superAdmin := new(model.SuperAdmin)
+	err = getRecord(stub, model.SuperAdminTable, []string{rotation.SuperAdminID}, superAdmin)
+	if err != nil {
+		return fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+	}
+
+	now, err := hUtil.GetTxTime(stub)
+	if err != nil {
+		return fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+	}
+	err = rotateKey(stub, superAdmin, rotation, now)
+	if err != nil || !apply {
+		return err
+	}
+
+	common.Logger.Infof("Rotate the key of SuperAdmin %s to version %d\n", superAdmin.SuperAdminID, superAdmin.KeyVersion)
+	err = util.Changeinfo(stub, model.SuperAdminTable, []string{superAdmin.SuperAdminID}, superAdmin)
+	if err != nil { // Return error: Fail to Update data
+		return fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR5], err.Error(), common.GetLine())
+	}
+	return nil
+}
+
+// keyRotationPayload func to build the canonical payload the current key signs. It names the next key version,
+// so the signature can't be replayed once the key has been rotated.
+func keyRotationPayload(superAdmin *model.SuperAdmin, rotation *model.KeyRotation) ([]byte, error) {
+	version, err := nextKeyVersion(superAdmin, rotation)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := model.KeyRotationPayload{
+		SuperAdminID:    superAdmin.SuperAdminID,
+		KeyVersion:      version,
+		PublicKey:       rotation.PublicKey,
+		SignatureScheme: rotation.SignatureScheme,
+		HashAlgorithm:   rotation.HashAlgorithm,
+	}
+	bytes, err := json.Marshal(payload)
+	if err != nil { // Return error: Can't marshal json
+		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR3], err.Error(), common.GetLine())
+	}
+	return bytes, nil
+}
+
+// nextKeyVersion func to get the version of the rotation's key, it must follow the current one
+func nextKeyVersion(superAdmin *model.SuperAdmin, rotation *model.KeyRotation) (int, error) {
+	version := currentKeyVersion(superAdmin) + 1
+	if rotation.KeyVersion != 0 && rotation.KeyVersion != version {
+		return 0, fmt.Errorf("The new key must be version %d %s", version, common.GetLine())
+	}
+	return version, nil
+}
+
+// rotateKey func to close the validity of the SuperAdmin's current key at now and make the rotation's key the current one.
+// The SuperAdmin is updated in place, the caller saves it.
+func rotateKey(stub shim.ChaincodeStubInterface, superAdmin *model.SuperAdmin, rotation *model.KeyRotation, now time.Time) error {
+	version, err := nextKeyVersion(superAdmin, rotation)
+	if err != nil {
+		return err
+	}
+
+	scheme, hashAlgorithm, err := hUtil.ResolveSignatureScheme(rotation.PublicKey, rotation.SignatureScheme, rotation.HashAlgorithm)
+	if err != nil {
+		return fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR6], err.Error(), common.GetLine())
+	}
+
+	// A key can't be used again, nor shared with another SuperAdmin
+	err = checkKeysNotEnrolled(stub, []string{rotation.PublicKey})
+	if err != nil {
+		return err
+	}
+
+	keys := keyHistory(superAdmin)
+
+	timestamp := now.UTC().Format(time.RFC3339)
+	keys[len(keys)-1].ValidUntil = timestamp
+	superAdmin.Keys = append(keys, model.SuperAdminKey{
+		KeyVersion:      version,
+		PublicKey:       rotation.PublicKey,
+		SignatureScheme: scheme,
+		HashAlgorithm:   hashAlgorithm,
+		ValidFrom:       timestamp,
+	})
+	superAdmin.KeyVersion = version
+	superAdmin.PublicKey = rotation.PublicKey
+	superAdmin.SignatureScheme = scheme
+	superAdmin.HashAlgorithm = hashAlgorithm
+
+	// A new key has its own WebAuthn counter
+	superAdmin.SignCount = 0
+	return nil
+}
+
+// startKeyHistory func to make the key of a new SuperAdmin its first version, valid from now
+func startKeyHistory(superAdmin *model.SuperAdmin, now time.Time) {
+	superAdmin.KeyVersion = 1
+	superAdmin.Keys = []model.SuperAdminKey{{
+		KeyVersion:      1,
+		PublicKey:       superAdmin.PublicKey,
+		SignatureScheme: superAdmin.SignatureScheme,
+		HashAlgorithm:   superAdmin.HashAlgorithm,
+		ValidFrom:       now.UTC().Format(time.RFC3339),
+	}}
+}
+
+// currentKeyVersion func to get the version of the SuperAdmin's key.
+// The key of a SuperAdmin enrolled before the history was kept is version 1.
+func currentKeyVersion(superAdmin *model.SuperAdmin) int {
+	if superAdmin.KeyVersion == 0 {
+		return 1
+	}
+	return superAdmin.KeyVersion
+}
+
+// keyHistory func to get the keys of the SuperAdmin, starting the history with the current key if it is missing
+func keyHistory(superAdmin *model.SuperAdmin) []model.SuperAdminKey {
+	if len(superAdmin.Keys) > 0 {
+		return superAdmin.Keys
+	}
+	return []model.SuperAdminKey{{
+		KeyVersion:      currentKeyVersion(superAdmin),
+		PublicKey:       superAdmin.PublicKey,
+		SignatureScheme: superAdmin.SignatureScheme,
+		HashAlgorithm:   superAdmin.HashAlgorithm,
+	}}
+}
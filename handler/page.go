@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/Akachain/akc-go-sdk/common"
+	"github.com/Akachain/hstx-go-sdk/model"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+// getPage func to get a page of the rows of a table. It scans the keys of the table, so it works on LevelDB.
+func getPage(stub shim.ChaincodeStubInterface, table string, newRecord func() interface{}, pageSizeStr string, bookmark string) (result *string, err error) {
+	pageSize, err := strconv.Atoi(pageSizeStr)
+	if err != nil {
+		pageSize = 0
+	}
+	err = checkPageSize(pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	resultsIterator, metadata, err := stub.GetStateByPartialCompositeKeyWithPagination(table, []string{}, int32(pageSize), bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+	}
+	return writePage(resultsIterator, metadata, newRecord)
+}
+
+// checkPageSize func to check the page size of a paginated query
+func checkPageSize(pageSize int) error {
+	if pageSize <= 0 || pageSize > model.MaxPageSize {
+		return fmt.Errorf("The page size must be between 1 and %d %s", model.MaxPageSize, common.GetLine())
+	}
+	return nil
+}
+
+// writePage func to encode a page of results. Each one is decoded into a new record from newRecord and encoded again,
+// so a page holds the same fields as util.GetAllData.
+func writePage(resultsIterator shim.StateQueryIteratorInterface, metadata *pb.QueryResponseMetadata, newRecord func() interface{}) (result *string, err error) {
+	defer resultsIterator.Close()
+
+	page := model.Page{Records: []json.RawMessage{}}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+		}
+
+		record := newRecord()
+		err = json.Unmarshal(queryResponse.Value, record)
+		if err != nil { // Convert JSON error
+			return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR3], err.Error(), common.GetLine())
+		}
+		bytes, err := json.Marshal(record)
+		if err != nil { // Return error: Can't marshal json
+			return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR3], err.Error(), common.GetLine())
+		}
+		page.Records = append(page.Records, bytes)
+	}
+	if metadata != nil {
+		page.Bookmark = metadata.Bookmark
+		page.FetchedRecordsCount = metadata.FetchedRecordsCount
+	} else {
+		page.FetchedRecordsCount = int32(len(page.Records))
+	}
+
+	bytes, err := json.Marshal(page)
+	if err != nil { // Return error: Can't marshal json
+		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR3], err.Error(), common.GetLine())
+	}
+	temp := ""
+	result = &temp
+	*result = string(bytes)
+
+	return result, nil
+}
@@ -3,7 +3,7 @@ package handler
 import (
 	"encoding/json"
 	"fmt"
-	"reflect"
+	"strconv"
 	"strings"
 	"time"
 
@@ -12,7 +12,6 @@ import (
 	"github.com/Akachain/hstx-go-sdk/model"
 	hUtil "github.com/Akachain/hstx-go-sdk/utils"
 	"github.com/hyperledger/fabric/core/chaincode/shim"
-	"github.com/mitchellh/mapstructure"
 )
 
 // ProposalHandler ...
@@ -28,15 +27,84 @@ func (sah *ProposalHandler) CreateProposal(stub shim.ChaincodeStubInterface, pro
 		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR3], err.Error(), common.GetLine())
 	}
 
+	// Check role: Admin
+	err = hUtil.HasRole(stub, hUtil.RoleAdmin)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s", err.Error(), common.GetLine())
+	}
+
+	// The caller must be the active Admin named in CreatedBy
+	admin, err := getBoundAdmin(stub, proposal.CreatedBy)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s", err.Error(), common.GetLine())
+	}
+	if admin.Status != "Active" {
+		return nil, fmt.Errorf("%s %s", "This Admin is not active", common.GetLine())
+	}
+
+	// Governance proposals are created by SuperAdmins through CreateGovernanceProposal
+	if len(proposal.Type) > 0 || len(proposal.Payload) > 0 {
+		return nil, fmt.Errorf("%s %s", "Type and Payload are only allowed in a governance proposal", common.GetLine())
+	}
+
+	// A policy is set by the same roles who can change it with UpdateProposal
+	if len(proposal.Policy) > 0 {
+		err = hUtil.HasRole(stub, proposalUpdateRules["Policy"].Roles...)
+		if err != nil {
+			return nil, fmt.Errorf("Not allowed to set the Policy. %s %s", err.Error(), common.GetLine())
+		}
+	}
+
+	return createProposal(stub, proposal)
+}
+
+// createProposal func to save a new Pending proposal whose creator has been checked
+func createProposal(stub shim.ChaincodeStubInterface, proposal *model.Proposal) (result *string, err error) {
 	proposal.ProposalID = hUtil.GenerateDocumentID(stub)
-	proposal.Status = "Pending"
+	proposal.Status = ""
+	proposal.Transitions = nil
+	proposal.CancelReason = ""
+
+	err = checkApprovalRule(stub, proposal)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s", err.Error(), common.GetLine())
+	}
+
+	// Remember who created the proposal, only this identity can cancel it besides the SuperAdmins
+	mspID, err := hUtil.GetMSPID(stub)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+	}
+	certID, err := hUtil.GetCertID(stub)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+	}
+	proposal.CreatorMSPID = *mspID
+	proposal.CreatorCertID = *certID
 
-	timestamp, err := stub.GetTxTimestamp()
+	// The proposal's lifetime is bounded by the configuration
+	config, err := loadConfig(stub)
 	if err != nil {
 		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
 	}
-	proposal.CreatedAt = time.Unix(timestamp.Seconds, 0).Format(time.RFC3339)
-	proposal.UpdatedAt = proposal.CreatedAt
+	if proposal.TTL == 0 {
+		proposal.TTL = config.DefaultProposalTTL
+	}
+	if proposal.TTL < 0 || proposal.TTL > config.MaxProposalTTL {
+		return nil, fmt.Errorf("The TTL must be between 1 and %d seconds %s", config.MaxProposalTTL, common.GetLine())
+	}
+
+	now, err := hUtil.GetTxTime(stub)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+	}
+	proposal.CreatedAt = now.Format(time.RFC3339)
+	proposal.ExpiresAt = now.Add(time.Duration(proposal.TTL) * time.Second).Format(time.RFC3339)
+
+	err = transitionProposal(stub, proposal, model.ProposalPending, now)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s", err.Error(), common.GetLine())
+	}
 
 	common.Logger.Infof("Create Proposal: %+v\n", proposal)
 	err = util.Createdata(stub, model.ProposalTable, []string{proposal.ProposalID}, &proposal)
@@ -64,18 +132,23 @@ func (sah *ProposalHandler) GetAllProposal(stub shim.ChaincodeStubInterface) (re
 	return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], res.Message, common.GetLine())
 }
 
+// GetProposalPage returns a page of the Proposals, pass the Bookmark of a page to get the next one
+func (sah *ProposalHandler) GetProposalPage(stub shim.ChaincodeStubInterface, pageSize string, bookmark string) (result *string, err error) {
+	common.Logger.Debugf("Input-data sent to GetProposalPage func: %+v %+v\n", pageSize, bookmark)
+
+	return getPage(stub, model.ProposalTable, func() interface{} { return new(model.Proposal) }, pageSize, bookmark)
+}
+
 // GetProposalByID ...
 func (sah *ProposalHandler) GetProposalByID(stub shim.ChaincodeStubInterface, proposalID string) (result *string, err error) {
 	common.Logger.Debugf("Input-data sent to GetProposalByID func: %+v\n", proposalID)
 
-	rawProposal, err := util.Getdatabyid(stub, proposalID, model.ProposalTable)
+	proposal := new(model.Proposal)
+	err = getRecord(stub, model.ProposalTable, []string{proposalID}, proposal)
 	if err != nil {
 		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
 	}
 
-	proposal := new(model.Proposal)
-	mapstructure.Decode(rawProposal, proposal)
-
 	bytes, err := json.Marshal(proposal)
 	if err != nil { // Return error: Can't marshal json
 		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR3], err.Error(), common.GetLine())
@@ -93,35 +166,33 @@ func (sah *ProposalHandler) GetPendingProposalBySuperAdminID(stub shim.Chaincode
 
 	var proposalList []model.Proposal
 
-	queryStr := fmt.Sprintf("{\"selector\": {\"_id\": {\"$regex\": \"%s\"},\"$or\": [{\"Status\": \"Pending\"},{\"Status\": \"Approved\"}]}}", model.ProposalTable)
-	resultsIterator, err := stub.GetQueryResult(queryStr)
+	now, err := hUtil.GetTxTime(stub)
 	if err != nil {
 		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
 	}
-	defer resultsIterator.Close()
-	for resultsIterator.HasNext() {
-		queryResponse, err := resultsIterator.Next()
-		if err != nil {
-			return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+
+	proposals, err := getProposalsByStatus(stub, model.ProposalPending, model.ProposalApproved)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+	}
+	for _, proposal := range proposals {
+		// Overdue proposals can't be signed anymore, even before ExpireProposals marks them
+		if isExpired(proposal, now) {
+			continue
 		}
 
-		proposal := new(model.Proposal)
-		err = json.Unmarshal(queryResponse.Value, proposal)
-		if err != nil { // Convert JSON error
-			return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR3], err.Error(), common.GetLine())
+		signed, err := getOptionalRecord(stub, model.ApprovalTable, []string{proposal.ProposalID, superAdminID}, nil)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
 		}
-		proposalList = append(proposalList, *proposal)
-	}
 
-	for i := len(proposalList) - 1; i >= 0; i-- {
-		proposal := proposalList[i]
-		rs, err := hUtil.GetByTwoColumns(stub, model.ApprovalTable, "ProposalID", fmt.Sprintf("\"%s\"", proposal.ProposalID), "ApproverID", fmt.Sprintf("\"%s\"", superAdminID))
+		// Skip the proposals restricted to other approvers
+		eligible, err := isEligible(stub, proposal, superAdminID)
 		if err != nil {
 			return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
 		}
-		if rs.HasNext() {
-			proposalList[i] = proposalList[len(proposalList)-1]
-			proposalList = proposalList[:len(proposalList)-1]
+		if !signed && eligible {
+			proposalList = append(proposalList, *proposal)
 		}
 	}
 
@@ -136,6 +207,83 @@ func (sah *ProposalHandler) GetPendingProposalBySuperAdminID(stub shim.Chaincode
 	return result, nil
 }
 
+// proposalUpdateRules lists the Proposal fields that can be changed by UpdateProposal
+var proposalUpdateRules = map[string]fieldRule{
+	"Message":           {},
+	"QuorumNumber":      {Roles: []string{hUtil.RoleSuperAdmin}},
+	"ThresholdWeight":   {Roles: []string{hUtil.RoleSuperAdmin}},
+	"Policy":            {Roles: []string{hUtil.RoleSuperAdmin}},
+	"RejectionRule":     {Roles: []string{hUtil.RoleSuperAdmin}},
+	"RejectionCount":    {Roles: []string{hUtil.RoleSuperAdmin}},
+	"EligibleApprovers": {Roles: []string{hUtil.RoleSuperAdmin}},
+	"EligibleGroups":    {Roles: []string{hUtil.RoleSuperAdmin}},
+}
+
+// checkApprovalRule func to check what the approvals of the proposal must reach: a weight or a policy, but not both,
+// and what rejects it
+func checkApprovalRule(stub shim.ChaincodeStubInterface, proposal *model.Proposal) error {
+	switch proposal.RejectionRule {
+	case "", model.RejectionVeto, model.RejectionImpossible:
+		if proposal.RejectionCount != 0 {
+			return fmt.Errorf("%s %s", "RejectionCount is only allowed with the Threshold rejection rule", common.GetLine())
+		}
+	case model.RejectionThreshold:
+		if proposal.RejectionCount < 1 {
+			return fmt.Errorf("%s %s", "The Threshold rejection rule needs a positive RejectionCount", common.GetLine())
+		}
+	default:
+		return fmt.Errorf("Unknown rejection rule %s %s", proposal.RejectionRule, common.GetLine())
+	}
+
+	if proposal.ThresholdWeight < 0 {
+		return fmt.Errorf("%s %s", "The ThresholdWeight can't be negative", common.GetLine())
+	}
+	err := checkEligibility(stub, proposal)
+	if err != nil {
+		return err
+	}
+	if len(proposal.Policy) > 0 {
+		if proposal.ThresholdWeight > 0 {
+			return fmt.Errorf("%s %s", "A proposal can't have both a ThresholdWeight and a Policy", common.GetLine())
+		}
+		return checkPolicy(stub, proposal.Policy)
+	}
+
+	// The governance quorum is checked when it is configured
+	if isGovernanceProposal(proposal) {
+		return nil
+	}
+	return checkQuorum(stub, proposal)
+}
+
+// checkQuorum func to check the QuorumNumber, or the ThresholdWeight, is at least the configured minimum
+// and can be reached by the active SuperAdmins eligible for the proposal
+func checkQuorum(stub shim.ChaincodeStubInterface, proposal *model.Proposal) error {
+	config, err := loadConfig(stub)
+	if err != nil {
+		return fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+	}
+	superAdmins, err := eligibleApprovers(stub, proposal)
+	if err != nil {
+		return fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+	}
+
+	if proposal.ThresholdWeight > 0 {
+		total := 0
+		for _, superAdmin := range superAdmins {
+			total += superAdminWeight(superAdmin)
+		}
+		if proposal.ThresholdWeight < config.MinQuorum || proposal.ThresholdWeight > total {
+			return fmt.Errorf("The ThresholdWeight must be between %d and %d %s", config.MinQuorum, total, common.GetLine())
+		}
+		return nil
+	}
+	if proposal.QuorumNumber < config.MinQuorum || proposal.QuorumNumber > len(superAdmins) {
+		return fmt.Errorf("The QuorumNumber must be between %d and %d %s", config.MinQuorum, len(superAdmins), common.GetLine())
+	}
+	return nil
+}
+
 //UpdateProposal ...
 func (sah *ProposalHandler) UpdateProposal(stub shim.ChaincodeStubInterface, proposalStr string) (result *string, err error) {
 	common.Logger.Debugf("Input-data sent to UpdateProposal func: %+v\n", proposalStr)
@@ -147,37 +295,56 @@ func (sah *ProposalHandler) UpdateProposal(stub shim.ChaincodeStubInterface, pro
 	}
 
 	if len(newProposal.ProposalID) == 0 {
-		return nil, fmt.Errorf("%s %s", "This ApprovalID can't be empty", common.GetLine())
+		return nil, fmt.Errorf("%s %s", "This ProposalID can't be empty", common.GetLine())
 	}
 
 	//get proposal information
-	rawProposal, err := util.Getdatabyid(stub, newProposal.ProposalID, model.ProposalTable)
+	proposal := new(model.Proposal)
+	err = getRecord(stub, model.ProposalTable, []string{newProposal.ProposalID}, proposal)
 	if err != nil {
 		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
 	}
 
-	proposal := new(model.Proposal)
-	mapstructure.Decode(rawProposal, proposal)
-
-	// Filter fields needed to update
-	newProposalValue := reflect.ValueOf(newProposal).Elem()
-	proposalValue := reflect.ValueOf(proposal).Elem()
-	for i := 0; i < newProposalValue.NumField(); i++ {
-		fieldName := newProposalValue.Type().Field(i).Name
-		if len(newProposalValue.Field(i).String()) > 0 {
-			field := proposalValue.FieldByName(fieldName)
-			if field.CanSet() {
-				fieldType := newProposalValue.Type().Field(i).Type
-				if strings.Compare("string", fieldType.String()) == 0 {
-					field.SetString(newProposalValue.Field(i).String())
-				}
-				if strings.Compare("int", fieldType.String()) == 0 {
-					field.SetInt(newProposalValue.Field(i).Int())
-				}
-			}
-		}
+	// A governance proposal is fixed, it can only be cancelled and created again
+	if isGovernanceProposal(proposal) {
+		return nil, fmt.Errorf("%s %s", "A governance proposal can't be updated", common.GetLine())
+	}
+
+	// Only Pending proposals can be changed
+	if proposal.Status != model.ProposalPending {
+		return nil, fmt.Errorf("The proposal can't be updated because it is %s %s", proposal.Status, common.GetLine())
+	}
+
+	now, err := hUtil.GetTxTime(stub)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+	}
+	if isExpired(proposal, now) {
+		return nil, fmt.Errorf("%s %s", "The proposal has expired", common.GetLine())
+	}
+
+	// Approvals are bound to the proposal's content, so it can't change once it has been signed
+	approvals, err := stub.GetStateByPartialCompositeKey(model.ApprovalTable, []string{proposal.ProposalID})
+	if err != nil {
+		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+	}
+	defer approvals.Close()
+	if approvals.HasNext() {
+		return nil, fmt.Errorf("%s %s", "The proposal can't be updated because it has already been signed", common.GetLine())
 	}
 
+	// Only copy the fields allowed to be updated
+	err = applyUpdate(stub, proposalStr, proposal, []string{"ProposalID"}, proposalUpdateRules)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s", err.Error(), common.GetLine())
+	}
+	err = checkApprovalRule(stub, proposal)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s", err.Error(), common.GetLine())
+	}
+
+	proposal.UpdatedAt = now.Format(time.RFC3339)
+
 	err = util.Changeinfo(stub, model.ProposalTable, []string{proposal.ProposalID}, proposal)
 	if err != nil { // Return error: Fail to Update data
 		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR5], err.Error(), common.GetLine())
@@ -198,36 +365,84 @@ func (sah *ProposalHandler) UpdateProposal(stub shim.ChaincodeStubInterface, pro
 func (sah *ProposalHandler) CommitProposal(stub shim.ChaincodeStubInterface, proposalID string) (result *string, err error) {
 	common.Logger.Debugf("Input-data sent to CommitProposal func: %+v\n", proposalID)
 
-	proposalStr, err := sah.GetProposalByID(stub, proposalID)
+	proposal := new(model.Proposal)
+	err = getRecord(stub, model.ProposalTable, []string{proposalID}, proposal)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+	}
+
+	if proposal.Status == model.ProposalPending {
+		return nil, fmt.Errorf("%s %s", "Not enough approval", common.GetLine())
+	}
+
+	now, err := hUtil.GetTxTime(stub)
 	if err != nil {
 		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
 	}
+	if isExpired(proposal, now) {
+		return nil, fmt.Errorf("%s %s", "The proposal has expired", common.GetLine())
+	}
+
+	// A governance proposal takes effect when it is committed
+	if isGovernanceProposal(proposal) {
+		err = checkTransition(proposal, model.ProposalCommitted)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s", err.Error(), common.GetLine())
+		}
+		err = applyGovernance(stub, proposal, true)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s", err.Error(), common.GetLine())
+		}
+	}
 
-	var proposal model.Proposal
-	err = json.Unmarshal([]byte(*proposalStr), &proposal)
+	err = saveTransition(stub, proposal, model.ProposalCommitted, now)
 	if err != nil {
+		return nil, fmt.Errorf("%s %s", err.Error(), common.GetLine())
+	}
+
+	bytes, err := json.Marshal(proposal)
+	if err != nil { // Return error: Can't marshal json
 		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR3], err.Error(), common.GetLine())
 	}
+	temp := ""
+	result = &temp
+	*result = string(bytes)
 
-	if strings.Compare("Pending", proposal.Status) == 0 {
-		return nil, fmt.Errorf("%s %s", "Not enough approval", common.GetLine())
+	return result, nil
+}
+
+// CancelProposal withdraws a Pending or Approved proposal. Only the identity which created it or a SuperAdmin can cancel it.
+func (sah *ProposalHandler) CancelProposal(stub shim.ChaincodeStubInterface, proposalID string, reason string) (result *string, err error) {
+	common.Logger.Debugf("Input-data sent to CancelProposal func: %+v %+v\n", proposalID, reason)
+
+	if len(strings.TrimSpace(reason)) == 0 {
+		return nil, fmt.Errorf("%s %s", "The reason can't be empty", common.GetLine())
 	}
 
-	if strings.Compare("Rejected", proposal.Status) == 0 {
-		return nil, fmt.Errorf("%s %s", "The proposal was rejected", common.GetLine())
+	proposal := new(model.Proposal)
+	err = getRecord(stub, model.ProposalTable, []string{proposalID}, proposal)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+	}
+
+	// Check the caller: the creator or a SuperAdmin
+	err = hUtil.IsInvoker(stub, proposal.CreatorMSPID, proposal.CreatorCertID)
+	if err != nil {
+		err = hUtil.IsSuperAdmin(stub)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s %s", "Only the creator of the proposal or a SuperAdmin can cancel it.", err.Error(), common.GetLine())
+		}
 	}
 
-	proposal.Status = "Committed"
-	timestamp, err := stub.GetTxTimestamp()
+	now, err := hUtil.GetTxTime(stub)
 	if err != nil {
 		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
 	}
-	updatedTime := time.Unix(timestamp.Seconds, 0)
-	proposal.UpdatedAt = updatedTime.String()
 
-	err = util.Changeinfo(stub, model.ProposalTable, []string{proposal.ProposalID}, proposal)
-	if err != nil { // Return error: Fail to Update data
-		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR5], err.Error(), common.GetLine())
+	proposal.CancelReason = reason
+	err = saveTransition(stub, proposal, model.ProposalCancelled, now)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s", err.Error(), common.GetLine())
 	}
 
 	bytes, err := json.Marshal(proposal)
@@ -240,3 +455,74 @@ func (sah *ProposalHandler) CommitProposal(stub shim.ChaincodeStubInterface, pro
 
 	return result, nil
 }
+
+// ExpireProposals marks at most batchSize overdue Pending or Approved proposals as Expired.
+// It can be called repeatedly, until it returns an empty list.
+func (sah *ProposalHandler) ExpireProposals(stub shim.ChaincodeStubInterface, batchSizeStr string) (result *string, err error) {
+	common.Logger.Debugf("Input-data sent to ExpireProposals func: %+v\n", batchSizeStr)
+
+	batchSize, err := strconv.Atoi(batchSizeStr)
+	if err != nil || batchSize <= 0 {
+		return nil, fmt.Errorf("%s %s", "The batch size must be a positive integer", common.GetLine())
+	}
+
+	now, err := hUtil.GetTxTime(stub)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+	}
+
+	// The expiry index is sorted by ExpiresAt, stored in UTC RFC3339, so the overdue proposals come first
+	deadline := now.Format(time.RFC3339)
+	proposalIDs := []string{}
+	err = scanIndex(stub, model.ProposalExpiryIndex, []string{}, func(keys []string) bool {
+		if keys[0] > deadline || len(proposalIDs) >= batchSize {
+			return false
+		}
+		proposalIDs = append(proposalIDs, keys[1])
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+	}
+
+	proposalList := []model.Proposal{}
+	for _, proposalID := range proposalIDs {
+		proposal := new(model.Proposal)
+		err = getRecord(stub, model.ProposalTable, []string{proposalID}, proposal)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+		}
+		if !isExpired(proposal, now) {
+			continue
+		}
+
+		err = saveTransition(stub, proposal, model.ProposalExpired, now)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s", err.Error(), common.GetLine())
+		}
+		proposalList = append(proposalList, *proposal)
+	}
+
+	bytes, err := json.Marshal(proposalList)
+	if err != nil { // Return error: Can't marshal json
+		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR3], err.Error(), common.GetLine())
+	}
+	temp := ""
+	result = &temp
+	*result = string(bytes)
+
+	return result, nil
+}
+
+// isExpired func to check whether the proposal's deadline has passed at now.
+// Proposals created before expiry was introduced have no deadline.
+func isExpired(proposal *model.Proposal, now time.Time) bool {
+	if len(proposal.ExpiresAt) == 0 {
+		return false
+	}
+	expiresAt, err := time.Parse(time.RFC3339, proposal.ExpiresAt)
+	if err != nil {
+		return false
+	}
+	return now.After(expiresAt)
+}
@@ -0,0 +1,236 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Akachain/akc-go-sdk/common"
+	"github.com/Akachain/hstx-go-sdk/model"
+	hUtil "github.com/Akachain/hstx-go-sdk/utils"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// proposalSortIndexes maps the fields a search can be sorted by to the design document and name of their index,
+// shipped in META-INF/statedb/couchdb/indexes
+var proposalSortIndexes = map[string][2]string{
+	model.SortByCreatedAt: {"indexProposalCreatedAtDoc", "indexProposalCreatedAt"},
+	model.SortByUpdatedAt: {"indexProposalUpdatedAtDoc", "indexProposalUpdatedAt"},
+}
+
+// SearchProposals returns a page of the proposals matching a ProposalSearch, sorted by CreatedAt or UpdatedAt.
+// The searches the secondary indexes can serve work on LevelDB, the other ones are CouchDB rich queries.
+func (sah *ProposalHandler) SearchProposals(stub shim.ChaincodeStubInterface, searchStr string) (result *string, err error) {
+	common.Logger.Debugf("Input-data sent to SearchProposals func: %+v\n", searchStr)
+
+	search := new(model.ProposalSearch)
+	err = json.Unmarshal([]byte(searchStr), search)
+	if err != nil { // Return error: Can't unmarshal json
+		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR3], err.Error(), common.GetLine())
+	}
+	err = checkPageSize(search.PageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	query, err := proposalSearchQuery(search)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s", err.Error(), common.GetLine())
+	}
+	if isIndexedSearch(search) {
+		return searchProposalIndex(stub, search)
+	}
+
+	resultsIterator, metadata, err := hUtil.GetQueryResultWithPagination(stub, query, int32(search.PageSize), search.Bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("%s This search needs CouchDB, on LevelDB a search must filter by CreatedBy or one Status "+
+			"and be sorted by ascending CreatedAt. Cause: %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+	}
+	return writePage(resultsIterator, metadata, func() interface{} { return new(model.Proposal) })
+}
+
+// isIndexedSearch func to check whether the secondary indexes can serve a search: it filters by CreatedBy or one Status,
+// which start the keys of the index, and only by CreatedAt otherwise, which follows them
+func isIndexedSearch(search *model.ProposalSearch) bool {
+	if search.CreatedBy == "" && len(search.Status) != 1 {
+		return false
+	}
+	return search.SortBy == model.SortByCreatedAt && !search.Descending && search.MessagePrefix == "" &&
+		search.UpdatedAfter == "" && search.UpdatedBefore == ""
+}
+
+// searchProposalIndex func to get a page of the proposals matching a search through the creator or the status index.
+// The entries of the index are read in chunks of the records still missing, so the bookmark is the key of the next entry.
+func searchProposalIndex(stub shim.ChaincodeStubInterface, search *model.ProposalSearch) (result *string, err error) {
+	index, prefix := model.ProposalStatusIndex, []string{string(search.Status[0])}
+	if search.CreatedBy != "" {
+		index, prefix = model.ProposalCreatorIndex, []string{search.CreatedBy}
+	}
+
+	bookmark := search.Bookmark
+	if bookmark == "" && search.CreatedAfter != "" {
+		// Start the scan at the first entry created after the bound
+		bookmark, err = stub.CreateCompositeKey(index, append(prefix, search.CreatedAfter))
+		if err != nil {
+			return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+		}
+	}
+
+	page := model.Page{Records: []json.RawMessage{}}
+	for len(page.Records) < search.PageSize {
+		requested := int32(search.PageSize - len(page.Records))
+		entries, metadata, err := stub.GetStateByPartialCompositeKeyWithPagination(index, prefix, requested, bookmark)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+		}
+		fetched, done, err := searchIndexEntries(stub, entries, prefix, search, &page)
+		entries.Close()
+		if err != nil {
+			return nil, err
+		}
+		bookmark = metadata.Bookmark
+		if done || fetched < requested || bookmark == "" {
+			bookmark = ""
+			break
+		}
+	}
+	page.Bookmark = bookmark
+	page.FetchedRecordsCount = int32(len(page.Records))
+
+	bytes, err := json.Marshal(page)
+	if err != nil { // Return error: Can't marshal json
+		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR3], err.Error(), common.GetLine())
+	}
+	temp := ""
+	result = &temp
+	*result = string(bytes)
+
+	return result, nil
+}
+
+// searchIndexEntries func to add the proposals of a chunk of index entries matching the search to the page.
+// It returns the number of entries read, and done once an entry is past CreatedBefore or outside the prefix.
+func searchIndexEntries(stub shim.ChaincodeStubInterface, entries shim.StateQueryIteratorInterface, prefix []string, search *model.ProposalSearch, page *model.Page) (int32, bool, error) {
+	fetched := int32(0)
+	for entries.HasNext() {
+		entry, err := entries.Next()
+		if err != nil {
+			return fetched, false, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+		}
+		fetched++
+		_, keys, err := stub.SplitCompositeKey(entry.Key)
+		if err != nil {
+			return fetched, false, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+		}
+
+		// A bookmark can't move the scan to the entries of another prefix
+		if len(keys) != 3 || keys[0] != prefix[0] {
+			return fetched, true, nil
+		}
+		createdAt, proposalID := keys[1], keys[2]
+		if search.CreatedBefore != "" && createdAt >= search.CreatedBefore {
+			return fetched, true, nil
+		}
+		if createdAt < search.CreatedAfter {
+			continue
+		}
+
+		proposal := new(model.Proposal)
+		err = getRecord(stub, model.ProposalTable, []string{proposalID}, proposal)
+		if err != nil {
+			return fetched, false, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+		}
+		if len(search.Status) > 0 && !containsStatus(search.Status, proposal.Status) {
+			continue
+		}
+		bytes, err := json.Marshal(proposal)
+		if err != nil { // Return error: Can't marshal json
+			return fetched, false, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR3], err.Error(), common.GetLine())
+		}
+		page.Records = append(page.Records, bytes)
+	}
+	return fetched, false, nil
+}
+
+// containsStatus reports whether status is in statuses
+func containsStatus(statuses []model.ProposalStatus, status model.ProposalStatus) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// proposalSearchQuery func to translate a ProposalSearch into a CouchDB query
+func proposalSearchQuery(search *model.ProposalSearch) (*hUtil.Query, error) {
+	if search.SortBy == "" {
+		search.SortBy = model.SortByCreatedAt
+	}
+	index, ok := proposalSortIndexes[search.SortBy]
+	if !ok {
+		return nil, fmt.Errorf("Proposals can only be sorted by %s or %s", model.SortByCreatedAt, model.SortByUpdatedAt)
+	}
+
+	bounds := map[string][]*string{
+		model.SortByCreatedAt: {&search.CreatedAfter, &search.CreatedBefore},
+		model.SortByUpdatedAt: {&search.UpdatedAfter, &search.UpdatedBefore},
+	}
+	selectors := []hUtil.Selector{hUtil.InTable(model.ProposalTable)}
+	for _, field := range []string{model.SortByCreatedAt, model.SortByUpdatedAt} {
+		for _, bound := range bounds[field] {
+			if *bound == "" {
+				continue
+			}
+			// Dates are stored in UTC RFC3339, so they can be compared as strings
+			date, err := time.Parse(time.RFC3339, *bound)
+			if err != nil {
+				return nil, fmt.Errorf("The %s dates must be RFC3339: %s", field, err.Error())
+			}
+			*bound = date.UTC().Format(time.RFC3339)
+		}
+		from, to := *bounds[field][0], *bounds[field][1]
+		// The sort field is always selected, so its index can be used
+		if from != "" || to != "" || field == search.SortBy {
+			selectors = append(selectors, hUtil.Range(field, from, to))
+		}
+	}
+
+	if len(search.Status) > 0 {
+		statuses := []interface{}{}
+		for _, status := range search.Status {
+			if !isProposalStatus(status) {
+				return nil, fmt.Errorf("Unknown proposal status %s", status)
+			}
+			statuses = append(statuses, status)
+		}
+		selectors = append(selectors, hUtil.In("Status", statuses...))
+	}
+	if search.CreatedBy != "" {
+		selectors = append(selectors, hUtil.Eq("CreatedBy", search.CreatedBy))
+	}
+	if search.MessagePrefix != "" {
+		selectors = append(selectors, hUtil.Prefix("Message", search.MessagePrefix))
+	}
+
+	direction := hUtil.Asc
+	if search.Descending {
+		direction = hUtil.Desc
+	}
+	return hUtil.NewQuery(selectors...).SortBy(search.SortBy, direction).WithIndex(index[0], index[1]), nil
+}
+
+// isProposalStatus func to check whether status is a state of a Proposal
+func isProposalStatus(status model.ProposalStatus) bool {
+	for from, statuses := range proposalTransitions {
+		if status == from && status != "" {
+			return true
+		}
+		for _, to := range statuses {
+			if status == to {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Akachain/hstx-go-sdk/model"
+	hUtil "github.com/Akachain/hstx-go-sdk/utils"
+	"gotest.tools/assert"
+)
+
+// TestQueryIndexes checks that a shipped index serves the query of every shape of proposal search
+func TestQueryIndexes(t *testing.T) {
+	indexes, err := hUtil.LoadIndexes("../" + hUtil.IndexDir)
+	assert.NilError(t, err)
+
+	dates := []model.ProposalSearch{
+		{},
+		{CreatedAfter: "2000-01-01T07:00:00+07:00"},
+		{CreatedBefore: "2100-01-01T00:00:00Z"},
+		{UpdatedAfter: "2000-01-01T00:00:00Z", UpdatedBefore: "2100-01-01T00:00:00Z"},
+		{CreatedAfter: "2000-01-01T00:00:00Z", UpdatedBefore: "2100-01-01T00:00:00Z"},
+	}
+	statuses := [][]model.ProposalStatus{
+		nil,
+		{model.ProposalPending},
+		{model.ProposalPending, model.ProposalApproved},
+	}
+
+	for _, sortBy := range []string{"", model.SortByCreatedAt, model.SortByUpdatedAt} {
+		for _, descending := range []bool{false, true} {
+			for _, status := range statuses {
+				for _, createdBy := range []string{"", "Admin"} {
+					for _, messagePrefix := range []string{"", "Pay"} {
+						for _, date := range dates {
+							search := date
+							search.SortBy = sortBy
+							search.Descending = descending
+							search.Status = status
+							search.CreatedBy = createdBy
+							search.MessagePrefix = messagePrefix
+
+							shape := fmt.Sprintf("%+v", search)
+							query, err := proposalSearchQuery(&search)
+							assert.NilError(t, err, shape)
+							assert.NilError(t, hUtil.CheckIndexed(query, indexes), shape)
+						}
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Akachain/akc-go-sdk/common"
+	"github.com/Akachain/akc-go-sdk/util"
+	"github.com/Akachain/hstx-go-sdk/model"
+	hUtil "github.com/Akachain/hstx-go-sdk/utils"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// proposalTransitions lists the statuses a Proposal can move to from each status.
+// Rejected, Committed, Cancelled and Expired are final.
+var proposalTransitions = map[model.ProposalStatus][]model.ProposalStatus{
+	"": {model.ProposalPending},
+	model.ProposalPending: {
+		model.ProposalApproved,
+		model.ProposalRejected,
+		model.ProposalCancelled,
+		model.ProposalExpired,
+	},
+	model.ProposalApproved: {
+		model.ProposalCommitted,
+		model.ProposalRejected,
+		model.ProposalCancelled,
+		model.ProposalExpired,
+	},
+}
+
+// IllegalTransitionError is returned when a Proposal can't move from its status to another one
+type IllegalTransitionError struct {
+	ProposalID string
+	From       model.ProposalStatus
+	To         model.ProposalStatus
+}
+
+func (e *IllegalTransitionError) Error() string {
+	return fmt.Sprintf("The proposal %s can't go from %s to %s", e.ProposalID, e.From, e.To)
+}
+
+// canTransition func to check whether a Proposal with status from can move to status to
+func canTransition(from model.ProposalStatus, to model.ProposalStatus) bool {
+	for _, allowed := range proposalTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// checkTransition func to check the proposal can move to status to, without changing it
+func checkTransition(proposal *model.Proposal, to model.ProposalStatus) error {
+	if !canTransition(proposal.Status, to) {
+		return &IllegalTransitionError{ProposalID: proposal.ProposalID, From: proposal.Status, To: to}
+	}
+	return nil
+}
+
+// transitionProposal func to move the proposal to status to and record who did it and when.
+// Every change of a Proposal's status must go through this function.
+// The proposal is updated in place and its index entries are moved, the caller saves it.
+func transitionProposal(stub shim.ChaincodeStubInterface, proposal *model.Proposal, to model.ProposalStatus, now time.Time) error {
+	err := checkTransition(proposal, to)
+	if err != nil {
+		return err
+	}
+
+	mspID, err := hUtil.GetMSPID(stub)
+	if err != nil {
+		return err
+	}
+	certID, err := hUtil.GetCertID(stub)
+	if err != nil {
+		return err
+	}
+
+	timestamp := now.UTC().Format(time.RFC3339)
+	from := proposal.Status
+	proposal.Transitions = append(proposal.Transitions, model.Transition{
+		From:      from,
+		To:        to,
+		MSPID:     *mspID,
+		Actor:     *certID,
+		Timestamp: timestamp,
+	})
+	proposal.Status = to
+	proposal.UpdatedAt = timestamp
+	return indexProposal(stub, proposal, from)
+}
+
+// saveTransition func to move an existing proposal to status to and save it
+func saveTransition(stub shim.ChaincodeStubInterface, proposal *model.Proposal, to model.ProposalStatus, now time.Time) error {
+	err := transitionProposal(stub, proposal, to, now)
+	if err != nil {
+		return err
+	}
+
+	common.Logger.Infof("Proposal %s is %s\n", proposal.ProposalID, proposal.Status)
+	err = util.Changeinfo(stub, model.ProposalTable, []string{proposal.ProposalID}, proposal)
+	if err != nil { // Return error: Fail to Update data
+		return fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR5], err.Error(), common.GetLine())
+	}
+	return nil
+}
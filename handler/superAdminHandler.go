@@ -3,7 +3,6 @@ package handler
 import (
 	"encoding/json"
 	"fmt"
-	"reflect"
 
 	"github.com/Akachain/akc-go-sdk/common"
 	"github.com/Akachain/akc-go-sdk/util"
@@ -16,42 +15,6 @@ import (
 // SuperAdminHandler ...
 type SuperAdminHandler struct{}
 
-// CreateSuperAdmin ...
-func (sah *SuperAdminHandler) CreateSuperAdmin(stub shim.ChaincodeStubInterface, superAdminStr string) (result *string, err error) {
-	common.Logger.Debugf("Input-data sent to CreateSuperAdmin func: %+v\n", superAdminStr)
-
-	err = hUtil.IsSuperAdmin(stub)
-	if err != nil {
-		return nil, fmt.Errorf("%s %s", err.Error(), common.GetLine())
-	}
-
-	superAdmin := new(model.SuperAdmin)
-	err = json.Unmarshal([]byte(superAdminStr), superAdmin)
-	if err != nil { // Return error: Can't unmarshal json
-		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR3], err.Error(), common.GetLine())
-	}
-
-	if superAdmin.Status == "" {
-		superAdmin.Status = "A"
-	}
-
-	common.Logger.Infof("Create SuperAdmin: %+v\n", superAdmin)
-	err = util.Createdata(stub, model.SuperAdminTable, []string{superAdmin.SuperAdminID}, &superAdmin)
-	if err != nil { // Return error: Fail to insert data
-		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR5], err.Error(), common.GetLine())
-	}
-
-	bytes, err := json.Marshal(superAdmin)
-	if err != nil { // Return error: Can't marshal json
-		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR3], err.Error(), common.GetLine())
-	}
-	temp := ""
-	result = &temp
-	*result = string(bytes)
-
-	return result, nil
-}
-
 // GetAllSuperAdmin ...
 func (sah *SuperAdminHandler) GetAllSuperAdmin(stub shim.ChaincodeStubInterface) (result *string, err error) {
 	res := util.GetAllData(stub, new(model.SuperAdmin), model.SuperAdminTable)
@@ -61,6 +24,13 @@ func (sah *SuperAdminHandler) GetAllSuperAdmin(stub shim.ChaincodeStubInterface)
 	return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], res.Message, common.GetLine())
 }
 
+// GetSuperAdminPage returns a page of the SuperAdmins, pass the Bookmark of a page to get the next one
+func (sah *SuperAdminHandler) GetSuperAdminPage(stub shim.ChaincodeStubInterface, pageSize string, bookmark string) (result *string, err error) {
+	common.Logger.Debugf("Input-data sent to GetSuperAdminPage func: %+v %+v\n", pageSize, bookmark)
+
+	return getPage(stub, model.SuperAdminTable, func() interface{} { return new(model.SuperAdmin) }, pageSize, bookmark)
+}
+
 // GetSuperAdminByID ...
 func (sah *SuperAdminHandler) GetSuperAdminByID(stub shim.ChaincodeStubInterface, superAdminID string) (result *string, err error) {
 	common.Logger.Debugf("Input-data sent to GetSuperAdminByID func: %+v\n", superAdminID)
@@ -84,6 +54,24 @@ func (sah *SuperAdminHandler) GetSuperAdminByID(stub shim.ChaincodeStubInterface
 	return result, nil
 }
 
+// superAdminUpdateRules lists the SuperAdmin fields a SuperAdmin can change by UpdateSuperAdmin,
+// the other ones can only be changed by a governance proposal
+var superAdminUpdateRules = map[string]fieldRule{
+	"Name": {},
+}
+
+// superAdminGovernanceRules lists the SuperAdmin fields that can be changed by an UpdateSuperAdmin proposal.
+// The key is only changed by a rotation, which keeps the previous ones.
+var superAdminGovernanceRules = map[string]fieldRule{
+	"Name":                    {},
+	"Status":                  {},
+	"Weight":                  {},
+	"RpID":                    {},
+	"RequireUserVerification": {},
+	"MSPID":                   {},
+	"CertID":                  {},
+}
+
 //UpdateSuperAdmin ...
 func (sah *SuperAdminHandler) UpdateSuperAdmin(stub shim.ChaincodeStubInterface, superAdminStr string) (result *string, err error) {
 	common.Logger.Debugf("Input-data sent to UpdateSuperAdmin func: %+v\n", superAdminStr)
@@ -100,29 +88,27 @@ func (sah *SuperAdminHandler) UpdateSuperAdmin(stub shim.ChaincodeStubInterface,
 	}
 
 	if len(newSuperAdmin.SuperAdminID) == 0 {
-		return nil, fmt.Errorf("%s %s", "This ApprovalID can't be empty", common.GetLine())
+		return nil, fmt.Errorf("%s %s", "This SuperAdminID can't be empty", common.GetLine())
 	}
 
-	// Get superAdmin information
-	rawSuperAdmin, err := util.Getdatabyid(stub, newSuperAdmin.SuperAdminID, model.SuperAdminTable)
+	// A SuperAdmin can only update itself
+	superAdmin, err := getBoundSuperAdmin(stub, newSuperAdmin.SuperAdminID)
 	if err != nil {
-		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+		return nil, fmt.Errorf("%s %s", err.Error(), common.GetLine())
 	}
 
-	superAdmin := new(model.SuperAdmin)
-	mapstructure.Decode(rawSuperAdmin, superAdmin)
+	// Only copy the fields allowed to be updated
+	err = applyUpdate(stub, superAdminStr, superAdmin, []string{"SuperAdminID"}, superAdminUpdateRules)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s", err.Error(), common.GetLine())
+	}
 
-	// Filter fields needed to update
-	newSuperAdminValue := reflect.ValueOf(newSuperAdmin).Elem()
-	superAdminValue := reflect.ValueOf(superAdmin).Elem()
-	for i := 0; i < newSuperAdminValue.NumField(); i++ {
-		fieldName := newSuperAdminValue.Type().Field(i).Name
-		if len(newSuperAdminValue.Field(i).String()) > 0 {
-			field := superAdminValue.FieldByName(fieldName)
-			if field.CanSet() {
-				field.SetString(newSuperAdminValue.Field(i).String())
-			}
-		}
+	err = validateSuperAdmin(superAdmin)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s", err.Error(), common.GetLine())
+	}
+	if len(superAdmin.MSPID) == 0 || len(superAdmin.CertID) == 0 {
+		return nil, fmt.Errorf("%s %s", "MSPID and CertID can't be empty", common.GetLine())
 	}
 
 	err = util.Changeinfo(stub, model.SuperAdminTable, []string{superAdmin.SuperAdminID}, superAdmin)
@@ -140,3 +126,149 @@ func (sah *SuperAdminHandler) UpdateSuperAdmin(stub shim.ChaincodeStubInterface,
 
 	return result, nil
 }
+
+// validateSuperAdmin func to check the values of a SuperAdmin before it is saved and fill the default signature scheme
+func validateSuperAdmin(superAdmin *model.SuperAdmin) error {
+	if superAdmin.Status != "A" && superAdmin.Status != "I" && superAdmin.Status != "Active" && superAdmin.Status != "Inactive" {
+		return fmt.Errorf("Invalid SuperAdmin status %s %s", superAdmin.Status, common.GetLine())
+	}
+
+	if superAdmin.Weight < 0 {
+		return fmt.Errorf("The weight of a SuperAdmin can't be negative %s", common.GetLine())
+	}
+
+	scheme, hashAlgorithm, err := hUtil.ResolveSignatureScheme(superAdmin.PublicKey, superAdmin.SignatureScheme, superAdmin.HashAlgorithm)
+	if err != nil {
+		return fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR6], err.Error(), common.GetLine())
+	}
+	superAdmin.SignatureScheme = scheme
+	superAdmin.HashAlgorithm = hashAlgorithm
+	return nil
+}
+
+// checkNewSuperAdmin func to fill the defaults of a SuperAdmin enrolled by Init or an AddSuperAdmin proposal and check it can be saved
+func checkNewSuperAdmin(stub shim.ChaincodeStubInterface, superAdmin *model.SuperAdmin) error {
+	if len(superAdmin.SuperAdminID) == 0 {
+		return fmt.Errorf("%s %s", "This SuperAdminID can't be empty", common.GetLine())
+	}
+	if superAdmin.Status == "" {
+		superAdmin.Status = "A"
+	}
+	superAdmin.SignCount = 0
+
+	// The SuperAdmin isn't enrolled by the identity it is bound to, so the identity must be given
+	if len(superAdmin.MSPID) == 0 || len(superAdmin.CertID) == 0 {
+		return fmt.Errorf("%s %s", "MSPID and CertID can't be empty", common.GetLine())
+	}
+
+	err := validateSuperAdmin(superAdmin)
+	if err != nil {
+		return err
+	}
+
+	now, err := hUtil.GetTxTime(stub)
+	if err != nil {
+		return fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+	}
+	startKeyHistory(superAdmin, now)
+
+	credentialIDs := map[string]bool{}
+	for i := range superAdmin.Credentials {
+		credential := &superAdmin.Credentials[i]
+		err = checkCredential(credential, now)
+		if err != nil {
+			return err
+		}
+		if credentialIDs[credential.CredentialID] {
+			return fmt.Errorf("The credential %s is enrolled twice %s", credential.CredentialID, common.GetLine())
+		}
+		credentialIDs[credential.CredentialID] = true
+	}
+	err = checkKeysNotEnrolled(stub, superAdminKeys(superAdmin))
+	if err != nil {
+		return err
+	}
+
+	found, err := getOptionalRecord(stub, model.SuperAdminTable, []string{superAdmin.SuperAdminID}, nil)
+	if err != nil {
+		return fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+	}
+	if found {
+		return fmt.Errorf("The SuperAdmin %s already exists %s", superAdmin.SuperAdminID, common.GetLine())
+	}
+	return nil
+}
+
+// applyAddSuperAdmin func to check the SuperAdmin of an AddSuperAdmin proposal and enrol it if apply is true
+func applyAddSuperAdmin(stub shim.ChaincodeStubInterface, payload string, apply bool) error {
+	superAdmin := new(model.SuperAdmin)
+	err := json.Unmarshal([]byte(payload), superAdmin)
+	if err != nil { // Return error: Can't unmarshal json
+		return fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR3], err.Error(), common.GetLine())
+	}
+
+	err = checkNewSuperAdmin(stub, superAdmin)
+	if err != nil || !apply {
+		return err
+	}
+
+	common.Logger.Infof("Create SuperAdmin: %+v\n", superAdmin)
+	err = util.Createdata(stub, model.SuperAdminTable, []string{superAdmin.SuperAdminID}, superAdmin)
+	if err != nil { // Return error: Fail to insert data
+		return fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR5], err.Error(), common.GetLine())
+	}
+	return nil
+}
+
+// applyUpdateSuperAdmin func to check the changes of an UpdateSuperAdmin proposal and save them if apply is true.
+// It can re-bind, deactivate or reactivate a SuperAdmin.
+func applyUpdateSuperAdmin(stub shim.ChaincodeStubInterface, payload string, apply bool) error {
+	newSuperAdmin := new(model.SuperAdmin)
+	err := json.Unmarshal([]byte(payload), newSuperAdmin)
+	if err != nil { // Return error: Can't unmarshal json
+		return fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR3], err.Error(), common.GetLine())
+	}
+
+	superAdmin := new(model.SuperAdmin)
+	err = getRecord(stub, model.SuperAdminTable, []string{newSuperAdmin.SuperAdminID}, superAdmin)
+	if err != nil {
+		return fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+	}
+	wasActive := isActiveSuperAdmin(superAdmin)
+
+	err = applyUpdate(stub, payload, superAdmin, []string{"SuperAdminID"}, superAdminGovernanceRules)
+	if err != nil {
+		return err
+	}
+
+	err = validateSuperAdmin(superAdmin)
+	if err != nil {
+		return err
+	}
+	if len(superAdmin.MSPID) == 0 || len(superAdmin.CertID) == 0 {
+		return fmt.Errorf("%s %s", "MSPID and CertID can't be empty", common.GetLine())
+	}
+
+	// Governance must still be able to reach its quorum
+	if wasActive && !isActiveSuperAdmin(superAdmin) {
+		err = checkGovernanceQuorum(stub, -1)
+		if err != nil {
+			return err
+		}
+	}
+	if !apply {
+		return nil
+	}
+
+	common.Logger.Infof("Update SuperAdmin: %+v\n", superAdmin)
+	err = util.Changeinfo(stub, model.SuperAdminTable, []string{superAdmin.SuperAdminID}, superAdmin)
+	if err != nil { // Return error: Fail to Update data
+		return fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR5], err.Error(), common.GetLine())
+	}
+	return nil
+}
+
+// isActiveSuperAdmin func to check whether the SuperAdmin can approve proposals
+func isActiveSuperAdmin(superAdmin *model.SuperAdmin) bool {
+	return superAdmin.Status == "A" || superAdmin.Status == "Active"
+}
@@ -0,0 +1,197 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/Akachain/akc-go-sdk/common"
+	"github.com/Akachain/hstx-go-sdk/model"
+	hUtil "github.com/Akachain/hstx-go-sdk/utils"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// GetProposalQuorumStatus reports how many SuperAdmins can approve the proposal and how many more approvals it needs
+func (sah *ProposalHandler) GetProposalQuorumStatus(stub shim.ChaincodeStubInterface, proposalID string) (result *string, err error) {
+	common.Logger.Debugf("Input-data sent to GetProposalQuorumStatus func: %+v\n", proposalID)
+
+	proposal := new(model.Proposal)
+	err = getRecord(stub, model.ProposalTable, []string{proposalID}, proposal)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+	}
+
+	votes, err := tallyVotes(stub, proposal, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+	}
+	superAdmins, err := eligibleApprovers(stub, proposal)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+	}
+	pending := pendingApprovers(superAdmins, votes)
+	remaining, err := remainingApprovals(stub, proposal, votes, pending)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine())
+	}
+
+	status := model.QuorumStatus{
+		ProposalID:         proposal.ProposalID,
+		Status:             proposal.Status,
+		EligibleApprovers:  len(superAdmins),
+		PendingApprovers:   len(pending),
+		Approvals:          len(votes.approved),
+		Rejections:         votes.rejections,
+		Abstentions:        votes.abstentions,
+		ApprovedWeight:     votes.weight,
+		RemainingApprovals: remaining,
+		Reachable:          remaining >= 0,
+	}
+	if len(proposal.Policy) == 0 {
+		status.Required = approvalThreshold(proposal)
+	}
+	if remaining < 0 {
+		status.RemainingApprovals = 0
+	}
+
+	bytes, err := json.Marshal(status)
+	if err != nil { // Return error: Can't marshal json
+		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR3], err.Error(), common.GetLine())
+	}
+	temp := ""
+	result = &temp
+	*result = string(bytes)
+
+	return result, nil
+}
+
+// tally holds the votes cast on a proposal
+type tally struct {
+	approved    map[string]bool // SuperAdmins who approved
+	voted       map[string]bool // SuperAdmins who approved, rejected or abstained
+	weight      int             // what the approvals add up to, see voteWeight
+	rejections  int
+	abstentions int
+}
+
+// tallyVotes func to count the approvals of the proposal. The new approval, if any, is counted
+// whether or not the query sees it, since a transaction doesn't read its own writes.
+func tallyVotes(stub shim.ChaincodeStubInterface, proposal *model.Proposal, approval *model.Approval) (*tally, error) {
+	votes := &tally{approved: map[string]bool{}, voted: map[string]bool{}}
+	if approval != nil {
+		votes.count(proposal, approval)
+	}
+
+	resIterator, err := hUtil.GetContainKey(stub, model.ApprovalTable, proposal.ProposalID)
+	if err != nil {
+		return nil, err
+	}
+	defer resIterator.Close()
+	for resIterator.HasNext() {
+		stateIterator, err := resIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		approvalState := new(model.Approval)
+		err = json.Unmarshal(stateIterator.Value, approvalState)
+		if err != nil { // Convert JSON error
+			return nil, err
+		}
+		if votes.voted[approvalState.ApproverID] {
+			continue
+		}
+		votes.count(proposal, approvalState)
+	}
+	return votes, nil
+}
+
+// count func to add the vote of an approval to the tally
+func (votes *tally) count(proposal *model.Proposal, approval *model.Approval) {
+	votes.voted[approval.ApproverID] = true
+	switch approval.Status {
+	case model.ApprovalApproved:
+		votes.approved[approval.ApproverID] = true
+		votes.weight += voteWeight(proposal, approval)
+	case model.ApprovalRejected:
+		votes.rejections++
+	case model.ApprovalAbstain:
+		votes.abstentions++
+	}
+}
+
+// approvalReached func to check whether the approvals reach the proposal's threshold or satisfy its policy
+func approvalReached(stub shim.ChaincodeStubInterface, proposal *model.Proposal, approved map[string]bool, weight int) (bool, error) {
+	if len(proposal.Policy) > 0 {
+		return policySatisfied(stub, proposal.Policy, approved)
+	}
+	return weight >= approvalThreshold(proposal), nil
+}
+
+// rejectionReached func to check whether the votes reject the proposal under its rejection rule
+func rejectionReached(stub shim.ChaincodeStubInterface, proposal *model.Proposal, votes *tally) (bool, error) {
+	switch proposal.RejectionRule {
+	case model.RejectionThreshold:
+		return votes.rejections >= proposal.RejectionCount, nil
+
+	case model.RejectionImpossible:
+		superAdmins, err := eligibleApprovers(stub, proposal)
+		if err != nil {
+			return false, err
+		}
+		remaining, err := remainingApprovals(stub, proposal, votes, pendingApprovers(superAdmins, votes))
+		return remaining < 0, err
+	}
+	return votes.rejections > 0, nil
+}
+
+// pendingApprovers func to list the eligible SuperAdmins who haven't voted on the proposal
+func pendingApprovers(superAdmins []*model.SuperAdmin, votes *tally) []*model.SuperAdmin {
+	pending := []*model.SuperAdmin{}
+	for _, superAdmin := range superAdmins {
+		if !votes.voted[superAdmin.SuperAdminID] {
+			pending = append(pending, superAdmin)
+		}
+	}
+	return pending
+}
+
+// remainingApprovals func to get how many of the pending approvers must still approve the proposal,
+// or -1 if they can't approve it any more
+func remainingApprovals(stub shim.ChaincodeStubInterface, proposal *model.Proposal, votes *tally, pending []*model.SuperAdmin) (int, error) {
+	if len(proposal.Policy) > 0 {
+		policy, err := hUtil.ParsePolicy(proposal.Policy)
+		if err != nil {
+			return 0, err
+		}
+		members, err := policyMembers(stub, policy)
+		if err != nil {
+			return 0, err
+		}
+		pendingIDs := map[string]bool{}
+		for _, superAdmin := range pending {
+			pendingIDs[superAdmin.SuperAdminID] = true
+		}
+		return policy.Needed(votes.approved, pendingIDs, members), nil
+	}
+
+	// The heaviest approvers first
+	weights := []int{}
+	for _, superAdmin := range pending {
+		weights = append(weights, voteWeight(proposal, &model.Approval{Weight: superAdminWeight(superAdmin)}))
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(weights)))
+
+	missing := approvalThreshold(proposal) - votes.weight
+	remaining := 0
+	for _, weight := range weights {
+		if missing <= 0 {
+			break
+		}
+		missing -= weight
+		remaining++
+	}
+	if missing > 0 {
+		return -1, nil
+	}
+	return remaining, nil
+}
@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/Akachain/akc-go-sdk/common"
+	hUtil "github.com/Akachain/hstx-go-sdk/utils"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// fieldRule describes who may change a field through an Update function
+type fieldRule struct {
+	Roles []string // the caller must own one of these roles, empty means any caller of the Update function
+}
+
+// applyUpdate copies the fields present in the JSON document updateStr into record, a pointer to a model struct.
+// Fields listed in rules are changed after checking the caller's role, fields listed in fixed (the IDs) are
+// accepted only if they keep their stored value and any other field is rejected as immutable.
+func applyUpdate(stub shim.ChaincodeStubInterface, updateStr string, record interface{}, fixed []string, rules map[string]fieldRule) error {
+	var fields map[string]json.RawMessage
+	err := json.Unmarshal([]byte(updateStr), &fields)
+	if err != nil { // Return error: Can't unmarshal json
+		return fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR3], err.Error(), common.GetLine())
+	}
+
+	// Walk the fields in a stable order so every endorser returns the same error
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	recordValue := reflect.ValueOf(record).Elem()
+	for _, name := range names {
+		field := fieldByJSONName(recordValue, name)
+		if !field.IsValid() {
+			return fmt.Errorf("Unknown field %s %s", name, common.GetLine())
+		}
+
+		newValue := reflect.New(field.Type())
+		err = json.Unmarshal(fields[name], newValue.Interface())
+		if err != nil { // Return error: Can't unmarshal json
+			return fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR3], err.Error(), common.GetLine())
+		}
+
+		if contains(fixed, name) {
+			if !reflect.DeepEqual(field.Interface(), newValue.Elem().Interface()) {
+				return fmt.Errorf("Field %s can't be changed %s", name, common.GetLine())
+			}
+			continue
+		}
+
+		rule, ok := rules[name]
+		if !ok {
+			return fmt.Errorf("Field %s is immutable %s", name, common.GetLine())
+		}
+		if len(rule.Roles) > 0 {
+			err = hUtil.HasRole(stub, rule.Roles...)
+			if err != nil {
+				return fmt.Errorf("Not allowed to update field %s. %s %s", name, err.Error(), common.GetLine())
+			}
+		}
+		field.Set(newValue.Elem())
+	}
+	return nil
+}
+
+// fieldByJSONName returns the field of a struct value whose json tag is name
+func fieldByJSONName(structValue reflect.Value, name string) reflect.Value {
+	structType := structValue.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		tag := strings.Split(structType.Field(i).Tag.Get("json"), ",")[0]
+		if tag == name {
+			return structValue.Field(i)
+		}
+	}
+	return reflect.Value{}
+}
+
+// contains reports whether value is in list
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/Akachain/akc-go-sdk/common"
 	hdl "github.com/Akachain/hstx-go-sdk/handler"
+	hUtil "github.com/Akachain/hstx-go-sdk/utils"
 	"github.com/hyperledger/fabric/core/chaincode/shim"
 	pb "github.com/hyperledger/fabric/protos/peer"
 )
@@ -13,173 +14,331 @@ import (
 type Chaincode struct {
 }
 
-var handler = new(hdl.Handler)
+var handler = hdl.NewHandler()
 
-// Init method is called when the Chain code" is instantiated by the blockchain network
-func (s *Chaincode) Init(stub shim.ChaincodeStubInterface) pb.Response {
-	return shim.Success(nil)
-}
-
-// Invoke method is called as a result of an application request to run the chain code
-// The calling application program has also specified the particular smart contract function to be called, with arguments
-func (s *Chaincode) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
-	common.Logger.Info("########### Hstx Invoke ###########")
+// functions registers every function exposed by the chaincode
+var functions = registerFunctions()
 
-	handler.InitHandler()
+func registerFunctions() *registry {
+	r := newRegistry()
 
-	// Retrieve the requested Smart Contract function and arguments
-	functionName, args := stub.GetFunctionAndParameters()
+	// Roles allowed to call the functions, Auditors can only read
+	superAdmins := []string{hUtil.RoleSuperAdmin}
+	admins := []string{hUtil.RoleAdmin}
+	managers := []string{hUtil.RoleSuperAdmin, hUtil.RoleAdmin}
+	readers := []string{hUtil.RoleSuperAdmin, hUtil.RoleAdmin, hUtil.RoleAuditor}
 
-	router := map[string]func(shim.ChaincodeStubInterface, []string) pb.Response{
-		"CreateSuperAdmin": createSuperAdmin,
-		"CreateAdmin": createAdmin,
-		"CreateProposal":   createProposal,
-		"CreateApproval":   createApproval,
-		"CommitProposal":   commitProposal,
-		// "UpdateSuperAdmin": handler.SuperAdminHandler.UpdateSuperAdmin,
-		// "UpdateAdmin":      handler.AdminHandler.UpdateAdmin,
-		// "UpdateProposal":   handler.ProposalHandler.UpdateProposal,
-		// "UpdateApproval":   handler.ApprovalHandler.UpdateApproval,
-	}
+	// SuperAdmin
+	r.register(chaincodeFunction{
+		Name:  "UpdateSuperAdmin",
+		Args:  []argSpec{{"SuperAdmin", argJSON}},
+		Roles: superAdmins,
+		call:  withStringArg(handler.SuperAdminHandler.UpdateSuperAdmin),
+	})
+	r.register(chaincodeFunction{
+		Name:  "RotateSuperAdminKey",
+		Args:  []argSpec{{"KeyRotation", argJSON}},
+		Roles: superAdmins,
+		call:  withStringArg(handler.SuperAdminHandler.RotateSuperAdminKey),
+	})
+	r.register(chaincodeFunction{
+		Name:  "RevokeSuperAdminCredential",
+		Args:  []argSpec{{"SuperAdminID", argString}, {"CredentialID", argString}},
+		Roles: superAdmins,
+		call:  withTwoStringArgs(handler.SuperAdminHandler.RevokeSuperAdminCredential),
+	})
+	r.register(chaincodeFunction{
+		Name:     "GetKeyRotationPayload",
+		Args:     []argSpec{{"KeyRotation", argJSON}},
+		ReadOnly: true,
+		Roles:    readers,
+		call:     withStringArg(handler.SuperAdminHandler.GetKeyRotationPayload),
+	})
+	r.register(chaincodeFunction{
+		Name:     "GetAllSuperAdmin",
+		ReadOnly: true,
+		Roles:    readers,
+		call:     withoutArgs(handler.SuperAdminHandler.GetAllSuperAdmin),
+	})
+	r.register(chaincodeFunction{
+		Name:     "GetSuperAdminPage",
+		Args:     []argSpec{{"PageSize", argInt}, {"Bookmark", argString}},
+		ReadOnly: true,
+		Roles:    readers,
+		call:     withTwoStringArgs(handler.SuperAdminHandler.GetSuperAdminPage),
+	})
+	r.register(chaincodeFunction{
+		Name:     "GetSuperAdminByID",
+		Args:     []argSpec{{"SuperAdminID", argString}},
+		ReadOnly: true,
+		Roles:    readers,
+		call:     withStringArg(handler.SuperAdminHandler.GetSuperAdminByID),
+	})
 
-	invokeFunc := router[functionName]
-	if invokeFunc != nil {
-		return invokeFunc(stub, args)
-	}
-	return s.Query(stub)
-}
+	// Admin
+	r.register(chaincodeFunction{
+		Name:  "CreateAdmin",
+		Args:  []argSpec{{"Admin", argJSON}},
+		Roles: superAdmins,
+		call:  withStringArg(handler.AdminHandler.CreateAdmin),
+	})
+	r.register(chaincodeFunction{
+		Name:  "UpdateAdmin",
+		Args:  []argSpec{{"Admin", argJSON}},
+		Roles: managers,
+		call:  withStringArg(handler.AdminHandler.UpdateAdmin),
+	})
+	r.register(chaincodeFunction{
+		Name:     "GetAllAdmin",
+		ReadOnly: true,
+		Roles:    readers,
+		call:     withoutArgs(handler.AdminHandler.GetAllAdmin),
+	})
+	r.register(chaincodeFunction{
+		Name:     "GetAdminPage",
+		Args:     []argSpec{{"PageSize", argInt}, {"Bookmark", argString}},
+		ReadOnly: true,
+		Roles:    readers,
+		call:     withTwoStringArgs(handler.AdminHandler.GetAdminPage),
+	})
+	r.register(chaincodeFunction{
+		Name:     "GetAdminByID",
+		Args:     []argSpec{{"AdminID", argString}},
+		ReadOnly: true,
+		Roles:    readers,
+		call:     withStringArg(handler.AdminHandler.GetAdminByID),
+	})
 
-// Query callback representing the query of a chaincode
-func (s *Chaincode) Query(stub shim.ChaincodeStubInterface) pb.Response {
-	common.Logger.Info("########### Hstx Query ###########")
+	// Proposal
+	r.register(chaincodeFunction{
+		Name:  "CreateProposal",
+		Args:  []argSpec{{"Proposal", argJSON}},
+		Roles: admins,
+		call:  withStringArg(handler.ProposalHandler.CreateProposal),
+	})
+	r.register(chaincodeFunction{
+		Name:  "UpdateProposal",
+		Args:  []argSpec{{"Proposal", argJSON}},
+		Roles: managers,
+		call:  withStringArg(handler.ProposalHandler.UpdateProposal),
+	})
+	r.register(chaincodeFunction{
+		Name:  "CommitProposal",
+		Args:  []argSpec{{"ProposalID", argString}},
+		Roles: managers,
+		call:  withStringArg(handler.ProposalHandler.CommitProposal),
+	})
+	r.register(chaincodeFunction{
+		Name:     "GetAllProposal",
+		ReadOnly: true,
+		Roles:    readers,
+		call:     withoutArgs(handler.ProposalHandler.GetAllProposal),
+	})
+	r.register(chaincodeFunction{
+		Name:     "GetProposalPage",
+		Args:     []argSpec{{"PageSize", argInt}, {"Bookmark", argString}},
+		ReadOnly: true,
+		Roles:    readers,
+		call:     withTwoStringArgs(handler.ProposalHandler.GetProposalPage),
+	})
+	r.register(chaincodeFunction{
+		Name:     "GetProposalByID",
+		Args:     []argSpec{{"ProposalID", argString}},
+		ReadOnly: true,
+		Roles:    readers,
+		call:     withStringArg(handler.ProposalHandler.GetProposalByID),
+	})
+	r.register(chaincodeFunction{
+		Name:     "GetPendingProposalBySuperAdminID",
+		Args:     []argSpec{{"SuperAdminID", argString}},
+		ReadOnly: true,
+		Roles:    readers,
+		call:     withStringArg(handler.ProposalHandler.GetPendingProposalBySuperAdminID),
+	})
+	r.register(chaincodeFunction{
+		Name:     "GetProposalQuorumStatus",
+		Args:     []argSpec{{"ProposalID", argString}},
+		ReadOnly: true,
+		Roles:    readers,
+		call:     withStringArg(handler.ProposalHandler.GetProposalQuorumStatus),
+	})
+	r.register(chaincodeFunction{
+		Name:     "SearchProposals",
+		Args:     []argSpec{{"ProposalSearch", argJSON}},
+		ReadOnly: true,
+		Roles:    readers,
+		call:     withStringArg(handler.ProposalHandler.SearchProposals),
+	})
+	r.register(chaincodeFunction{
+		Name:  "CancelProposal",
+		Args:  []argSpec{{"ProposalID", argString}, {"Reason", argString}},
+		Roles: managers,
+		call:  withTwoStringArgs(handler.ProposalHandler.CancelProposal),
+	})
+	r.register(chaincodeFunction{
+		Name:  "ExpireProposals",
+		Args:  []argSpec{{"BatchSize", argInt}},
+		Roles: managers,
+		call:  withStringArg(handler.ProposalHandler.ExpireProposals),
+	})
+	r.register(chaincodeFunction{
+		Name:  "RebuildIndexes",
+		Args:  []argSpec{{"BatchSize", argInt}, {"StartKey", argString}},
+		Roles: superAdmins,
+		call:  withTwoStringArgs(handler.ProposalHandler.RebuildIndexes),
+	})
 
-	// Retrieve the requested Smart Contract function and arguments
-	functionName, args := stub.GetFunctionAndParameters()
+	// Approval
+	r.register(chaincodeFunction{
+		Name:  "CreateApproval",
+		Args:  []argSpec{{"Approval", argJSON}},
+		Roles: superAdmins,
+		call:  withStringArg(handler.ApprovalHandler.CreateApproval),
+	})
+	r.register(chaincodeFunction{
+		Name:  "RequestApprovalChallenge",
+		Args:  []argSpec{{"ProposalID", argString}, {"ApproverID", argString}},
+		Roles: superAdmins,
+		call:  withTwoStringArgs(handler.ApprovalHandler.RequestApprovalChallenge),
+	})
+	r.register(chaincodeFunction{
+		Name:     "GetApprovalPayload",
+		Args:     []argSpec{{"ProposalID", argString}, {"ApproverID", argString}, {"Status", argString}},
+		ReadOnly: true,
+		Roles:    readers,
+		call:     withThreeStringArgs(handler.ApprovalHandler.GetApprovalPayload),
+	})
+	r.register(chaincodeFunction{
+		Name:     "GetAllApproval",
+		ReadOnly: true,
+		Roles:    readers,
+		call:     withoutArgs(handler.ApprovalHandler.GetAllApproval),
+	})
+	r.register(chaincodeFunction{
+		Name:     "GetApprovalPage",
+		Args:     []argSpec{{"PageSize", argInt}, {"Bookmark", argString}},
+		ReadOnly: true,
+		Roles:    readers,
+		call:     withTwoStringArgs(handler.ApprovalHandler.GetApprovalPage),
+	})
+	r.register(chaincodeFunction{
+		Name:     "GetApprovalByID",
+		Args:     []argSpec{{"ApprovalID", argString}},
+		ReadOnly: true,
+		Roles:    readers,
+		call:     withStringArg(handler.ApprovalHandler.GetApprovalByID),
+	})
+	r.register(chaincodeFunction{
+		Name:     "GetApprovalBySuperAdminID",
+		Args:     []argSpec{{"SuperAdminID", argString}},
+		ReadOnly: true,
+		Roles:    readers,
+		call:     withStringArg(handler.ApprovalHandler.GetApprovalBySuperAdminID),
+	})
 
-	router := map[string]func(shim.ChaincodeStubInterface, []string) pb.Response{
-		// "GetAllSuperAdmin":                 handler.SuperAdminHandler.GetAllSuperAdmin,
-		// "GetSuperAdminByID":                handler.SuperAdminHandler.GetSuperAdminByID,
-		// "GetAllAdmin":                      handler.AdminHandler.GetAllAdmin,
-		// "GetAdminByID":                     handler.AdminHandler.GetAdminByID,
-		// "GetAllProposal":                   handler.ProposalHandler.GetAllProposal,
-		// "GetProposalByID":                  handler.ProposalHandler.GetProposalByID,
-		// "GetPendingProposalBySuperAdminID": handler.ProposalHandler.GetPendingProposalBySuperAdminID,
-		// "GetAllApproval":                   handler.ApprovalHandler.GetAllApproval,
-		// "GetApprovalByID":                  handler.ApprovalHandler.GetApprovalByID,
-	}
+	// ApproverGroup: created and changed through governance proposals
+	r.register(chaincodeFunction{
+		Name:     "GetAllApproverGroup",
+		ReadOnly: true,
+		Roles:    readers,
+		call:     withoutArgs(handler.ApproverGroupHandler.GetAllApproverGroup),
+	})
+	r.register(chaincodeFunction{
+		Name:     "GetApproverGroupPage",
+		Args:     []argSpec{{"PageSize", argInt}, {"Bookmark", argString}},
+		ReadOnly: true,
+		Roles:    readers,
+		call:     withTwoStringArgs(handler.ApproverGroupHandler.GetApproverGroupPage),
+	})
+	r.register(chaincodeFunction{
+		Name:     "GetApproverGroupByID",
+		Args:     []argSpec{{"GroupID", argString}},
+		ReadOnly: true,
+		Roles:    readers,
+		call:     withStringArg(handler.ApproverGroupHandler.GetApproverGroupByID),
+	})
 
-	queryFunc := router[functionName]
-	return queryFunc(stub, args)
-}
+	// Governance: SuperAdmins, ApproverGroups and Config only change through these proposals
+	r.register(chaincodeFunction{
+		Name:  "CreateGovernanceProposal",
+		Args:  []argSpec{{"Proposal", argJSON}},
+		Roles: superAdmins,
+		call:  withStringArg(handler.GovernanceHandler.CreateGovernanceProposal),
+	})
 
-// createSuperAdmin
-func createSuperAdmin(stub shim.ChaincodeStubInterface, args []string) pb.Response {
-	superAdminStr := args[0]
+	// Config
+	r.register(chaincodeFunction{
+		Name:     "GetConfig",
+		ReadOnly: true,
+		Roles:    readers,
+		call:     withoutArgs(handler.ConfigHandler.GetConfig),
+	})
 
-	created, err := handler.SuperAdminHandler.CreateSuperAdmin(stub, superAdminStr)
-	if err != nil {
-		// Returning error: Can't create data
-		return common.RespondError(common.ResponseError{
-			ResCode: common.ERR4,
-			Msg:     fmt.Sprintf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine()),
-		})
-	}
+	// Introspection
+	r.register(chaincodeFunction{
+		Name:     "GetFunctions",
+		ReadOnly: true,
+		call:     withoutArgs(r.describe),
+	})
 
-	// Returning success with payload is created data
-	resSuc := common.ResponseSuccess{
-		ResCode: common.SUCCESS,
-		Msg:     common.ResCodeDict[common.SUCCESS],
-		Payload: *created,
-	}
-	return common.RespondSuccess(resSuc)
+	return r
 }
 
-// createAdmin
-func createAdmin(stub shim.ChaincodeStubInterface, args []string) pb.Response {
-	adminStr := args[0]
+// Init method is called when the Chain code" is instantiated or upgraded by the blockchain network.
+// At instantiation its argument is the Genesis: the first SuperAdmins and the governance quorum.
+// An upgrade without argument keeps the ledger as it is, with the arguments BindSuperAdmins and the bindings
+// it binds the SuperAdmins created before the identity binding.
+func (s *Chaincode) Init(stub shim.ChaincodeStubInterface) pb.Response {
+	common.Logger.Info("########### Hstx Init ###########")
 
-	created, err := handler.AdminHandler.CreateAdmin(stub, adminStr)
-	if err != nil {
-		// Returning error: Can't create data
+	_, args := stub.GetFunctionAndParameters()
+	if len(args) == 0 {
+		return shim.Success(nil)
+	}
+	bind := len(args) == 2 && args[0] == "BindSuperAdmins"
+	if len(args) > 1 && !bind {
+		// Returning error: Invalid arguments
 		return common.RespondError(common.ResponseError{
-			ResCode: common.ERR4,
-			Msg:     fmt.Sprintf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine()),
+			ResCode: common.ERR2,
+			Msg:     fmt.Sprintf("%s Init expects the Genesis, or BindSuperAdmins and the bindings %s", common.ResCodeDict[common.ERR2], common.GetLine()),
 		})
 	}
 
-	// Returning success with payload is created data
-	resSuc := common.ResponseSuccess{
-		ResCode: common.SUCCESS,
-		Msg:     common.ResCodeDict[common.SUCCESS],
-		Payload: *created,
+	var err error
+	if bind {
+		err = handler.GovernanceHandler.BindSuperAdmins(stub, args[1])
+	} else {
+		err = handler.GovernanceHandler.Bootstrap(stub, args[0])
 	}
-	return common.RespondSuccess(resSuc)
-}
-
-// createProposal
-func createProposal(stub shim.ChaincodeStubInterface, args []string) pb.Response {
-	proposalStr := args[0]
-
-	created, err := handler.ProposalHandler.CreateProposal(stub, proposalStr)
 	if err != nil {
-		// Returning error: Can't create data
+		// Returning error: Bootstrap or binding failed
 		return common.RespondError(common.ResponseError{
 			ResCode: common.ERR4,
 			Msg:     fmt.Sprintf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine()),
 		})
 	}
-
-	// Returning success with payload is created data
-	resSuc := common.ResponseSuccess{
-		ResCode: common.SUCCESS,
-		Msg:     common.ResCodeDict[common.SUCCESS],
-		Payload: *created,
-	}
-	return common.RespondSuccess(resSuc)
+	return shim.Success(nil)
 }
 
-// createApproval
-func createApproval(stub shim.ChaincodeStubInterface, args []string) pb.Response {
-	approvalStr := args[0]
+// Invoke method is called as a result of an application request to run the chain code
+// The calling application program has also specified the particular smart contract function to be called, with arguments
+func (s *Chaincode) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
+	common.Logger.Info("########### Hstx Invoke ###########")
 
-	created, err := handler.ApprovalHandler.CreateApproval(stub, approvalStr)
-	if err != nil {
-		// Returning error: Can't create data
-		return common.RespondError(common.ResponseError{
-			ResCode: common.ERR4,
-			Msg:     fmt.Sprintf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine()),
-		})
-	}
+	// Retrieve the requested Smart Contract function and arguments
+	functionName, args := stub.GetFunctionAndParameters()
 
-	// Returning success with payload is created data
-	resSuc := common.ResponseSuccess{
-		ResCode: common.SUCCESS,
-		Msg:     common.ResCodeDict[common.SUCCESS],
-		Payload: *created,
-	}
-	return common.RespondSuccess(resSuc)
+	return functions.dispatch(stub, functionName, args, false)
 }
 
-// commitProposal
-func commitProposal(stub shim.ChaincodeStubInterface, args []string) pb.Response {
-	approvalStr := args[0]
+// Query callback representing the query of a chaincode
+func (s *Chaincode) Query(stub shim.ChaincodeStubInterface) pb.Response {
+	common.Logger.Info("########### Hstx Query ###########")
 
-	created, err := handler.ProposalHandler.CommitProposal(stub, approvalStr)
-	if err != nil {
-		// Returning error: Can't create data
-		return common.RespondError(common.ResponseError{
-			ResCode: common.ERR4,
-			Msg:     fmt.Sprintf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine()),
-		})
-	}
+	// Retrieve the requested Smart Contract function and arguments
+	functionName, args := stub.GetFunctionAndParameters()
 
-	// Returning success with payload is created data
-	resSuc := common.ResponseSuccess{
-		ResCode: common.SUCCESS,
-		Msg:     common.ResCodeDict[common.SUCCESS],
-		Payload: *created,
-	}
-	return common.RespondSuccess(resSuc)
+	return functions.dispatch(stub, functionName, args, true)
 }
 
 // The main function is only relevant in unit test mode. Only included here for completeness.
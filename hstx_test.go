@@ -1,15 +1,37 @@
 package main
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
 	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/Akachain/hstx-go-sdk/model"
+	hUtil "github.com/Akachain/hstx-go-sdk/utils"
 	"gotest.tools/assert"
 
 	"github.com/Akachain/akc-go-sdk/common"
 	"github.com/Akachain/akc-go-sdk/util"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/hyperledger/fabric/bccsp/utils"
+	"github.com/hyperledger/fabric/common/attrmgr"
+	"github.com/hyperledger/fabric/core/chaincode/lib/cid"
 	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/protos/ledger/queryresult"
+	"github.com/hyperledger/fabric/protos/msp"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	protoutils "github.com/hyperledger/fabric/protos/utils"
+	uuid "github.com/satori/go.uuid"
 )
 
 var superAdminID string
@@ -17,6 +39,7 @@ var adminID string
 var proposalID string
 var approvalID string
 var stub = setupMock(false)
+var superAdminKey, _ = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
 
 func setupMock(isDropDB bool) *util.MockStubExtend {
 
@@ -30,38 +53,242 @@ func setupMock(isDropDB bool) *util.MockStubExtend {
 	return stub
 }
 
-func TestCreateSuperAdmin(t *testing.T) {
+// identityStub invokes the chaincode as the identity of a certificate, which MockStub doesn't support
+type identityStub struct {
+	*util.MockStubExtend
+	args      [][]byte
+	creator   []byte
+	paginated bool // a paginated query ran in the transaction
+}
+
+func (s *identityStub) GetCreator() ([]byte, error) { return s.creator, nil }
+
+func (s *identityStub) GetArgs() [][]byte { return s.args }
+
+func (s *identityStub) GetStringArgs() []string {
+	args := make([]string, 0, len(s.args))
+	for _, arg := range s.args {
+		args = append(args, string(arg))
+	}
+	return args
+}
+
+func (s *identityStub) GetFunctionAndParameters() (string, []string) {
+	args := s.GetStringArgs()
+	if len(args) == 0 {
+		return "", []string{}
+	}
+	return args[0], args[1:]
+}
+
+// executedQueries records the rich queries run through identityStub, see TestSecondaryIndexes
+var executedQueries = map[string]bool{}
+
+func (s *identityStub) GetQueryResult(query string) (shim.StateQueryIteratorInterface, error) {
+	executedQueries[query] = true
+	return s.MockStubExtend.GetQueryResult(query)
+}
+
+func (s *identityStub) GetQueryResultWithPagination(query string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *pb.QueryResponseMetadata, error) {
+	executedQueries[query] = true
+	s.paginated = true
+	return s.MockStubExtend.GetQueryResultWithPagination(query, pageSize, bookmark)
+}
+
+// GetStateByPartialCompositeKeyWithPagination pages through GetStateByPartialCompositeKey, which MockStubExtend
+// doesn't do. Like on a peer, the bookmark is the first key of the page and the returned one the first key of the next page.
+func (s *identityStub) GetStateByPartialCompositeKeyWithPagination(objectType string, keys []string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *pb.QueryResponseMetadata, error) {
+	s.paginated = true
+	iterator, err := s.GetStateByPartialCompositeKey(objectType, keys)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer iterator.Close()
+
+	page := &pageIterator{}
+	next := ""
+	for iterator.HasNext() {
+		row, err := iterator.Next()
+		if err != nil {
+			return nil, nil, err
+		}
+		if row.Key < bookmark {
+			continue
+		}
+		if int32(len(page.rows)) == pageSize {
+			next = row.Key
+			break
+		}
+		page.rows = append(page.rows, row)
+	}
+	return page, &pb.QueryResponseMetadata{FetchedRecordsCount: int32(len(page.rows)), Bookmark: next}, nil
+}
+
+// errPaginatedWrite is returned like a peer does for a write in a transaction which ran a paginated query
+var errPaginatedWrite = errors.New("Transaction has already performed a paginated query. Writes are not allowed")
+
+func (s *identityStub) PutState(key string, value []byte) error {
+	if s.paginated {
+		return errPaginatedWrite
+	}
+	return s.MockStubExtend.PutState(key, value)
+}
+
+func (s *identityStub) DelState(key string) error {
+	if s.paginated {
+		return errPaginatedWrite
+	}
+	return s.MockStubExtend.DelState(key)
+}
+
+// pageIterator iterates over a page of rows
+type pageIterator struct {
+	rows []*queryresult.KV
+}
+
+func (it *pageIterator) HasNext() bool { return len(it.rows) > 0 }
+
+func (it *pageIterator) Next() (*queryresult.KV, error) {
+	row := it.rows[0]
+	it.rows = it.rows[1:]
+	return row, nil
+}
+
+func (it *pageIterator) Close() error { return nil }
+
+// newIdentity builds a serialized identity whose certificate carries the 'hstx.role' attribute
+func newIdentity(mspID string, name string, role string) []byte {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	attrmgr.New().AddAttributesToCert(&attrmgr.Attributes{Attrs: map[string]string{"hstx.role": role}}, template)
+	template.ExtraExtensions = template.Extensions
+	der, _ := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	return protoutils.MarshalOrPanic(&msp.SerializedIdentity{Mspid: mspID, IdBytes: certPEM})
+}
+
+// identityID returns the MSP ID and certificate ID a record must be bound to for creator
+func identityID(creator []byte) (string, string) {
+	s := &identityStub{creator: creator}
+	mspID, _ := cid.GetMSPID(s)
+	certID, _ := cid.GetID(s)
+	return mspID, certID
+}
+
+var superAdminIdentity = newIdentity("Org1MSP", "superadmin", "SuperAdmin")
+var adminIdentity = newIdentity("Org1MSP", "admin", "Admin")
+var auditorIdentity = newIdentity("Org1MSP", "auditor", "Auditor")
+
+// invokeAs creates a mock invoke transaction sent by creator
+func invokeAs(t *testing.T, creator []byte, args [][]byte) string {
+	return invokeAt(t, creator, args, time.Now())
+}
+
+// invokeAt invokes the chaincode like invokeAs, in a transaction timestamped at
+func invokeAt(t *testing.T, creator []byte, args [][]byte, at time.Time) string {
+	txID := uuid.Must(uuid.NewV4()).String()
+	stub.MockTransactionStart(txID)
+	stub.TxTimestamp = &timestamp.Timestamp{Seconds: at.Unix(), Nanos: int32(at.Nanosecond())}
+	res := new(Chaincode).Invoke(&identityStub{MockStubExtend: stub, args: args, creator: creator})
+	stub.MockTransactionEnd(txID)
+	if res.Status != shim.OK {
+		return res.Message
+	}
+	return string(res.Payload)
+}
+
+// publicKeyPEM encodes a public key the way SuperAdmins are enrolled
+func publicKeyPEM(pk *ecdsa.PublicKey) string {
+	der, _ := x509.MarshalPKIXPublicKey(pk)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+// requestChallenge issues a challenge to the SuperAdmin for approving the proposal
+func requestChallenge(t *testing.T, proposalID string) string {
+	response := invokeAs(t, superAdminIdentity, [][]byte{[]byte("RequestApprovalChallenge"), []byte(proposalID), []byte(superAdminID)})
+	var challenge model.Challenge
+	json.Unmarshal([]byte(response), &challenge)
+	return challenge.Nonce
+}
+
+// signApprovalPayload fetches the payload of a proposal and signs it with the SuperAdmin's key
+func signApprovalPayload(t *testing.T, proposalID string, status string) (string, string) {
+	response := invokeAs(t, superAdminIdentity, [][]byte{[]byte("GetApprovalPayload"), []byte(proposalID), []byte(superAdminID), []byte(status)})
+	var payload map[string]string
+	json.Unmarshal([]byte(response), &payload)
+
+	data, _ := base64.StdEncoding.DecodeString(payload["Message"])
+	return payload["Message"], sign(superAdminKey, data)
+}
+
+// sign returns the base64 DER ECDSA signature of data with key
+func sign(key *ecdsa.PrivateKey, data []byte) string {
+	hash := sha256.Sum256(data)
+	r, s, _ := ecdsa.Sign(rand.Reader, key, hash[:])
+	signature, _ := utils.MarshalECDSASignature(r, s)
+	return base64.StdEncoding.EncodeToString(signature)
+}
+
+// approveProposal approves the proposal as the SuperAdmin
+func approveProposal(t *testing.T, proposalID string) model.Approval {
+	return vote(t, proposalID, model.ApprovalApproved)
+}
+
+// vote approves, rejects or abstains on the proposal as the SuperAdmin
+func vote(t *testing.T, proposalID string, status string) model.Approval {
+	challenge := requestChallenge(t, proposalID)
+	message, signature := signApprovalPayload(t, proposalID, status)
+	approvalBytes, _ := json.Marshal(model.Approval{
+		ProposalID: proposalID,
+		ApproverID: superAdminID,
+		Challenge:  challenge,
+		Signature:  signature,
+		Message:    message,
+		Status:     status,
+	})
+	response := invokeAs(t, superAdminIdentity, [][]byte{[]byte("CreateApproval"), approvalBytes})
+	var approval model.Approval
+	json.Unmarshal([]byte(response), &approval)
+	return approval
+}
+
+func TestInit(t *testing.T) {
 	common.Logger.SetLevel(shim.LogDebug)
 	
 	stub = setupMock(true)
 
 	superAdminID = "SYduDJxe6-MeAqyzGYqUB9LXK0e79o63OH2Tp7npcGdMG_IfaN6WAqfIfs388HlHjW9PIE2tP7MPGxzof6406g"
 
+	superAdminMSPID, superAdminCertID := identityID(superAdminIdentity)
 	superAdmin := model.SuperAdmin{
 		SuperAdminID: superAdminID,
 		Name:         "TestSuperAdmin" + superAdminID,
-		PublicKey:    "-----BEGIN PUBLIC KEY-----\nMFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAEeSohIzvASeU0nZADWDFS/WI/0+AU\n8tRQrGtRtaZ2hv6JCdq+BK1euL4VFPqVw5ddYbw0+t4b5zQDah7qrP41ug==\n-----END PUBLIC KEY-----\n",
+		PublicKey:    publicKeyPEM(&superAdminKey.PublicKey),
 		Status:       "A",
+		MSPID:        superAdminMSPID,
+		CertID:       superAdminCertID,
 	}
-
-	superAdminBytes, _ := json.Marshal(superAdmin)
-
-	// Create a new Super Admin
-	response := util.MockInvokeTransaction(t, stub, [][]byte{[]byte("CreateSuperAdmin"), superAdminBytes})
-	var result map[string]interface{}
-	json.Unmarshal([]byte(response), &result)
-	if result["status"] != nil {
-		common.Logger.Debug("Error: ", result["msg"])
+	genesis := model.Genesis{
+		SuperAdmins:      []model.SuperAdmin{superAdmin},
+		GovernanceQuorum: 1,
 	}
 
-	var createdSuperAdmin model.SuperAdmin
-	json.Unmarshal([]byte(response), &createdSuperAdmin)
-	common.Logger.Debugf("%v", createdSuperAdmin)
+	// The quorum must be reachable by the genesis SuperAdmins
+	genesis.GovernanceQuorum = 2
+	genesisBytes, _ := json.Marshal(genesis)
+	response := util.MockInitTransaction(t, stub, [][]byte{[]byte("init"), genesisBytes})
+	assert.Assert(t, strings.Contains(response, "The governance quorum must be between 1 and 1"), response)
 
-	assert.Equal(t, superAdmin.SuperAdminID, createdSuperAdmin.SuperAdminID)
-	assert.Equal(t, superAdmin.Name, createdSuperAdmin.Name)
-	assert.Equal(t, superAdmin.PublicKey, createdSuperAdmin.PublicKey)
-	assert.Equal(t, superAdmin.Status, createdSuperAdmin.Status)
+	// Enrol the genesis SuperAdmin
+	genesis.GovernanceQuorum = 1
+	genesisBytes, _ = json.Marshal(genesis)
+	response = util.MockInitTransaction(t, stub, [][]byte{[]byte("init"), genesisBytes})
+	assert.Equal(t, "", response)
 
 	// Check if the created data exists
 	compositeKey, _ := stub.CreateCompositeKey(model.SuperAdminTable, []string{superAdmin.SuperAdminID})
@@ -75,6 +302,25 @@ func TestCreateSuperAdmin(t *testing.T) {
 	assert.Equal(t, superAdmin.Name, stateSuperAdmin.Name)
 	assert.Equal(t, superAdmin.PublicKey, stateSuperAdmin.PublicKey)
 	assert.Equal(t, superAdmin.Status, stateSuperAdmin.Status)
+	assert.Equal(t, superAdmin.CertID, stateSuperAdmin.CertID)
+	assert.Equal(t, 1, stateSuperAdmin.KeyVersion)
+	assert.Equal(t, 1, len(stateSuperAdmin.Keys))
+
+	response = invokeAs(t, superAdminIdentity, [][]byte{[]byte("GetConfig")})
+	var config model.Config
+	json.Unmarshal([]byte(response), &config)
+	assert.Equal(t, 1, config.GovernanceQuorum)
+
+	// The genesis can't be replayed and SuperAdmins can't be created directly any more
+	response = util.MockInitTransaction(t, stub, [][]byte{[]byte("init"), genesisBytes})
+	assert.Assert(t, strings.Contains(response, "already been enrolled"), response)
+	superAdminBytes, _ := json.Marshal(superAdmin)
+	response = invokeAs(t, superAdminIdentity, [][]byte{[]byte("CreateSuperAdmin"), superAdminBytes})
+	assert.Assert(t, strings.Contains(response, "Unknown function"), response)
+
+	// An upgrade without argument keeps the ledger
+	response = util.MockInitTransaction(t, stub, [][]byte{[]byte("init")})
+	assert.Equal(t, "", response)
 }
 
 func TestCreateAdmin(t *testing.T) {
@@ -84,14 +330,22 @@ func TestCreateAdmin(t *testing.T) {
 		stub = setupMock(false)
 	}
 
+	adminMSPID, adminCertID := identityID(adminIdentity)
 	admin := model.Admin{
-		Name: "SonPH",
+		Name:   "SonPH",
+		MSPID:  adminMSPID,
+		CertID: adminCertID,
 	}
 
-	adminBytes, _ := json.Marshal(admin)
+	// The Admin's identity must be given
+	adminBytes, _ := json.Marshal(model.Admin{Name: admin.Name})
+	response := invokeAs(t, superAdminIdentity, [][]byte{[]byte("CreateAdmin"), adminBytes})
+	assert.Assert(t, strings.Contains(response, "MSPID and CertID can't be empty"), response)
+
+	adminBytes, _ = json.Marshal(admin)
 
 	// Create a new Admin
-	response := util.MockInvokeTransaction(t, stub, [][]byte{[]byte("CreateAdmin"), adminBytes})
+	response = invokeAs(t, superAdminIdentity, [][]byte{[]byte("CreateAdmin"), adminBytes})
 	var result map[string]interface{}
 	json.Unmarshal([]byte(response), &result)
 	if result["status"] != nil {
@@ -128,7 +382,7 @@ func TestCreateProposal(t *testing.T) {
 	}
 
 	proposal := model.Proposal{
-		CreatedBy: "Admin1",
+		CreatedBy: adminID,
 		Message: "Chuyển 1 tỷ cho anh Long sex",
 		QuorumNumber: 1,
 	}
@@ -136,7 +390,7 @@ func TestCreateProposal(t *testing.T) {
 	proposalBytes, _ := json.Marshal(proposal)
 
 	// Create a new Proposal
-	response := util.MockInvokeTransaction(t, stub, [][]byte{[]byte("CreateProposal"), proposalBytes})
+	response := invokeAs(t, adminIdentity, [][]byte{[]byte("CreateProposal"), proposalBytes})
 	var result map[string]interface{}
 	json.Unmarshal([]byte(response), &result)
 	if result["status"] != nil {
@@ -149,7 +403,7 @@ func TestCreateProposal(t *testing.T) {
 
 	assert.Equal(t, proposal.CreatedBy, createdProposal.CreatedBy)
 	assert.Equal(t, proposal.Message, createdProposal.Message)
-	assert.Equal(t, "Pending", createdProposal.Status)
+	assert.Equal(t, model.ProposalPending, createdProposal.Status)
 
 	// Check if the created data exists
 	compositeKey, _ := stub.CreateCompositeKey(model.ProposalTable, []string{createdProposal.ProposalID})
@@ -167,6 +421,13 @@ func TestCreateProposal(t *testing.T) {
 	assert.Equal(t, createdProposal.UpdatedAt, stateProposal.UpdatedAt)
 
 	proposalID = stateProposal.ProposalID
+
+	// Another identity can't create a proposal on behalf of the Admin
+	otherAdminIdentity := newIdentity("Org1MSP", "otheradmin", "Admin")
+	response = invokeAs(t, otherAdminIdentity, [][]byte{[]byte("CreateProposal"), proposalBytes})
+	result = nil
+	json.Unmarshal([]byte(response), &result)
+	assert.Equal(t, common.ERR4, result["status"])
 }
 
 func TestCreateApproval(t *testing.T) {
@@ -176,12 +437,15 @@ func TestCreateApproval(t *testing.T) {
 		stub = setupMock(false)
 	}
 
+	challenge := requestChallenge(t, proposalID)
+	message, signature := signApprovalPayload(t, proposalID, "Approved")
+
 	approval := model.Approval{
 		ProposalID: proposalID,
 		ApproverID: superAdminID,
-		Challenge: "Q2h1eeG7g24gMSB04bu3IGNobyBhbmggTG9uZyBz4bq9",
-		Signature: "MEUCIQDaeCaR1s33mIse0i5hq69srNfBHTajF47pibvJoLY7KwIgD/eerEBfeOTizizYIinZ7WLJ90UFpS6IBuEe0sqpYaM=",
-		Message: "EWJcgjcFkTyobMwx1j6p2xtEmKE8H/ctu5jr4jVilUgBAAAABSpqAxztD4zx7PrOdW1ur4ba/eE1yjhPtYY2QCNcOz4N",
+		Challenge: challenge,
+		Signature: signature,
+		Message: message,
 		Status: "Approved",
 		// Status: "Rejected",
 	}
@@ -189,7 +453,7 @@ func TestCreateApproval(t *testing.T) {
 	approvalBytes, _ := json.Marshal(approval)
 
 	// Create a new Approval
-	response := util.MockInvokeTransaction(t, stub, [][]byte{[]byte("CreateApproval"), approvalBytes})
+	response := invokeAs(t, superAdminIdentity, [][]byte{[]byte("CreateApproval"), approvalBytes})
 	
 	var result map[string]interface{}
 	json.Unmarshal([]byte(response), &result)
@@ -208,6 +472,7 @@ func TestCreateApproval(t *testing.T) {
 	assert.Equal(t, approval.Signature, createdApproval.Signature)
 	assert.Equal(t, approval.Message, createdApproval.Message)
 	assert.Equal(t, approval.Status, createdApproval.Status)
+	assert.Equal(t, 1, createdApproval.KeyVersion)
 
 	// Check if the created data exists
 	compositeKey, _ := stub.CreateCompositeKey(model.ApprovalTable, []string{createdApproval.ProposalID, createdApproval.ApproverID})
@@ -234,7 +499,7 @@ func TestCommitProposal(t *testing.T) {
 	}
 
 	// Create a new Approval
-	response := util.MockInvokeTransaction(t, stub, [][]byte{[]byte("CommitProposal"), []byte(proposalID)})
+	response := invokeAs(t, adminIdentity, [][]byte{[]byte("CommitProposal"), []byte(proposalID)})
 	
 	var result map[string]interface{}
 	json.Unmarshal([]byte(response), &result)
@@ -263,4 +528,935 @@ func TestCommitProposal(t *testing.T) {
 	assert.Equal(t, proposal.Status, stateProposal.Status)
 	assert.Equal(t, proposal.CreatedAt, stateProposal.CreatedAt)
 	assert.Equal(t, proposal.UpdatedAt, stateProposal.UpdatedAt)
-}
\ No newline at end of file
+}
+func TestQueryRouter(t *testing.T) {
+	common.Logger.SetLevel(shim.LogDebug)
+
+	if stub == nil {
+		stub = setupMock(false)
+	}
+
+	// Query an existing proposal
+	response := invokeAs(t, auditorIdentity, [][]byte{[]byte("GetProposalByID"), []byte(proposalID)})
+
+	var proposal model.Proposal
+	json.Unmarshal([]byte(response), &proposal)
+	assert.Equal(t, proposalID, proposal.ProposalID)
+
+	// Auditors can't write
+	response = invokeAs(t, auditorIdentity, [][]byte{[]byte("CommitProposal"), []byte(proposalID)})
+	var denied map[string]interface{}
+	json.Unmarshal([]byte(response), &denied)
+	assert.Equal(t, common.ERR4, denied["status"])
+
+	// Identities without role can't read
+	response = util.MockInvokeTransaction(t, stub, [][]byte{[]byte("GetProposalByID"), []byte(proposalID)})
+	denied = nil
+	json.Unmarshal([]byte(response), &denied)
+	assert.Equal(t, common.ERR4, denied["status"])
+
+	// Unknown function must be rejected instead of crashing the chaincode
+	response = util.MockInvokeTransaction(t, stub, [][]byte{[]byte("GetNothing")})
+	var result map[string]interface{}
+	json.Unmarshal([]byte(response), &result)
+	assert.Equal(t, common.ERR2, result["status"])
+
+	// Missing argument must be rejected
+	response = util.MockInvokeTransaction(t, stub, [][]byte{[]byte("GetProposalByID")})
+	result = nil
+	json.Unmarshal([]byte(response), &result)
+	assert.Equal(t, common.ERR2, result["status"])
+}
+
+func TestGetFunctions(t *testing.T) {
+	common.Logger.SetLevel(shim.LogDebug)
+
+	if stub == nil {
+		stub = setupMock(false)
+	}
+
+	response := util.MockInvokeTransaction(t, stub, [][]byte{[]byte("GetFunctions")})
+
+	var list []map[string]interface{}
+	json.Unmarshal([]byte(response), &list)
+
+	names := make(map[string]bool)
+	for _, f := range list {
+		names[f["Name"].(string)] = true
+	}
+	assert.Assert(t, names["CreateProposal"])
+	assert.Assert(t, names["GetProposalByID"])
+}
+
+func TestUpdateAdmin(t *testing.T) {
+	common.Logger.SetLevel(shim.LogDebug)
+
+	if stub == nil {
+		stub = setupMock(false)
+	}
+
+	// Fix the Admin's name
+	update := map[string]interface{}{"AdminID": adminID, "Name": "SonPH2"}
+	updateBytes, _ := json.Marshal(update)
+	response := invokeAs(t, adminIdentity, [][]byte{[]byte("UpdateAdmin"), updateBytes})
+
+	var updatedAdmin model.Admin
+	json.Unmarshal([]byte(response), &updatedAdmin)
+	assert.Equal(t, adminID, updatedAdmin.AdminID)
+	assert.Equal(t, "SonPH2", updatedAdmin.Name)
+
+	// Fields outside the whitelist are rejected
+	update = map[string]interface{}{"AdminID": adminID, "Unknown": "value"}
+	updateBytes, _ = json.Marshal(update)
+	response = invokeAs(t, adminIdentity, [][]byte{[]byte("UpdateAdmin"), updateBytes})
+
+	var result map[string]interface{}
+	json.Unmarshal([]byte(response), &result)
+	assert.Equal(t, common.ERR4, result["status"])
+
+	// The stored Admin keeps its status
+	compositeKey, _ := stub.CreateCompositeKey(model.AdminTable, []string{adminID})
+	state, _ := stub.GetState(compositeKey)
+
+	var stateAdmin model.Admin
+	json.Unmarshal([]byte(state), &stateAdmin)
+	assert.Equal(t, "SonPH2", stateAdmin.Name)
+	assert.Equal(t, "Active", stateAdmin.Status)
+}
+
+func TestProposalTTL(t *testing.T) {
+	common.Logger.SetLevel(shim.LogDebug)
+
+	if stub == nil {
+		stub = setupMock(false)
+	}
+
+	// Without TTL, the proposal lives for the default TTL
+	proposal := model.Proposal{CreatedBy: adminID, Message: "Expiring proposal", QuorumNumber: 1}
+	proposalBytes, _ := json.Marshal(proposal)
+	response := invokeAs(t, adminIdentity, [][]byte{[]byte("CreateProposal"), proposalBytes})
+
+	var createdProposal model.Proposal
+	json.Unmarshal([]byte(response), &createdProposal)
+	createdAt, _ := time.Parse(time.RFC3339, createdProposal.CreatedAt)
+	expiresAt, _ := time.Parse(time.RFC3339, createdProposal.ExpiresAt)
+	assert.Equal(t, 24*time.Hour, expiresAt.Sub(createdAt))
+
+	// The TTL can't exceed the configured maximum
+	proposal.TTL = 8 * 24 * 60 * 60
+	proposalBytes, _ = json.Marshal(proposal)
+	response = invokeAs(t, adminIdentity, [][]byte{[]byte("CreateProposal"), proposalBytes})
+
+	var result map[string]interface{}
+	json.Unmarshal([]byte(response), &result)
+	assert.Equal(t, common.ERR4, result["status"])
+}
+
+func TestCancelProposal(t *testing.T) {
+	common.Logger.SetLevel(shim.LogDebug)
+
+	if stub == nil {
+		stub = setupMock(false)
+	}
+
+	proposal := model.Proposal{CreatedBy: adminID, Message: "Mistaken proposal", QuorumNumber: 1}
+	proposalBytes, _ := json.Marshal(proposal)
+	response := invokeAs(t, adminIdentity, [][]byte{[]byte("CreateProposal"), proposalBytes})
+
+	var createdProposal model.Proposal
+	json.Unmarshal([]byte(response), &createdProposal)
+	assert.Equal(t, "Org1MSP", createdProposal.CreatorMSPID)
+
+	// Another Admin can't cancel it
+	otherAdminIdentity := newIdentity("Org1MSP", "otheradmin", "Admin")
+	response = invokeAs(t, otherAdminIdentity, [][]byte{[]byte("CancelProposal"), []byte(createdProposal.ProposalID), []byte("Not mine")})
+	var result map[string]interface{}
+	json.Unmarshal([]byte(response), &result)
+	assert.Equal(t, common.ERR4, result["status"])
+
+	// The creator can
+	response = invokeAs(t, adminIdentity, [][]byte{[]byte("CancelProposal"), []byte(createdProposal.ProposalID), []byte("Wrong amount")})
+	var cancelledProposal model.Proposal
+	json.Unmarshal([]byte(response), &cancelledProposal)
+	assert.Equal(t, model.ProposalCancelled, cancelledProposal.Status)
+	assert.Equal(t, "Wrong amount", cancelledProposal.CancelReason)
+
+	// Each change of status is recorded
+	assert.Equal(t, 2, len(cancelledProposal.Transitions))
+	assert.Equal(t, model.ProposalStatus(""), cancelledProposal.Transitions[0].From)
+	assert.Equal(t, model.ProposalPending, cancelledProposal.Transitions[1].From)
+	assert.Equal(t, model.ProposalCancelled, cancelledProposal.Transitions[1].To)
+	assert.Equal(t, "Org1MSP", cancelledProposal.Transitions[1].MSPID)
+	assert.Equal(t, cancelledProposal.UpdatedAt, cancelledProposal.Transitions[1].Timestamp)
+
+	// A cancelled proposal can't be committed
+	response = invokeAs(t, adminIdentity, [][]byte{[]byte("CommitProposal"), []byte(createdProposal.ProposalID)})
+	result = nil
+	json.Unmarshal([]byte(response), &result)
+	assert.Equal(t, common.ERR4, result["status"])
+	assert.Assert(t, strings.Contains(result["msg"].(string), "can't go from Cancelled to Committed"))
+}
+
+func TestGovernanceProposal(t *testing.T) {
+	common.Logger.SetLevel(shim.LogDebug)
+
+	if stub == nil {
+		stub = setupMock(false)
+	}
+
+	secondKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	secondIdentity := newIdentity("Org2MSP", "secondsuperadmin", "SuperAdmin")
+	secondMSPID, secondCertID := identityID(secondIdentity)
+	secondSuperAdmin := model.SuperAdmin{
+		SuperAdminID: "SecondSuperAdmin",
+		Name:         "Second SuperAdmin",
+		PublicKey:    publicKeyPEM(&secondKey.PublicKey),
+		MSPID:        secondMSPID,
+		CertID:       secondCertID,
+	}
+	payload, _ := json.Marshal(secondSuperAdmin)
+	proposal := model.Proposal{
+		CreatedBy:    superAdminID,
+		Message:      "Enrol a second SuperAdmin",
+		QuorumNumber: 5,
+		Type:         model.ProposalAddSuperAdmin,
+		Payload:      string(payload),
+	}
+	proposalBytes, _ := json.Marshal(proposal)
+
+	// Admins can't create governance proposals
+	adminProposal := proposal
+	adminProposal.CreatedBy = adminID
+	adminProposalBytes, _ := json.Marshal(adminProposal)
+	response := invokeAs(t, adminIdentity, [][]byte{[]byte("CreateProposal"), adminProposalBytes})
+	assert.Assert(t, strings.Contains(response, "only allowed in a governance proposal"), response)
+
+	// The governance quorum replaces the requested one
+	response = invokeAs(t, superAdminIdentity, [][]byte{[]byte("CreateGovernanceProposal"), proposalBytes})
+	var createdProposal model.Proposal
+	json.Unmarshal([]byte(response), &createdProposal)
+	assert.Equal(t, model.ProposalPending, createdProposal.Status)
+	assert.Equal(t, 1, createdProposal.QuorumNumber)
+
+	// A governance proposal is fixed
+	update, _ := json.Marshal(map[string]interface{}{"ProposalID": createdProposal.ProposalID, "Message": "Changed"})
+	response = invokeAs(t, superAdminIdentity, [][]byte{[]byte("UpdateProposal"), update})
+	assert.Assert(t, strings.Contains(response, "can't be updated"), response)
+
+	// Deactivating the only SuperAdmin would leave governance without quorum
+	deactivate, _ := json.Marshal(map[string]interface{}{"SuperAdminID": superAdminID, "Status": "I"})
+	deactivateProposal, _ := json.Marshal(model.Proposal{CreatedBy: superAdminID, Type: model.ProposalUpdateSuperAdmin, Payload: string(deactivate)})
+	response = invokeAs(t, superAdminIdentity, [][]byte{[]byte("CreateGovernanceProposal"), deactivateProposal})
+	assert.Assert(t, strings.Contains(response, "can't be reached"), response)
+
+	// A SuperAdmin can't change its own key directly
+	rekey, _ := json.Marshal(map[string]interface{}{"SuperAdminID": superAdminID, "PublicKey": secondSuperAdmin.PublicKey})
+	response = invokeAs(t, superAdminIdentity, [][]byte{[]byte("UpdateSuperAdmin"), rekey})
+	assert.Assert(t, strings.Contains(response, "Field PublicKey is immutable"), response)
+
+	// The SuperAdmin is enrolled when the approved proposal is committed
+	approveProposal(t, createdProposal.ProposalID)
+	response = invokeAs(t, adminIdentity, [][]byte{[]byte("CommitProposal"), []byte(createdProposal.ProposalID)})
+	var committedProposal model.Proposal
+	json.Unmarshal([]byte(response), &committedProposal)
+	assert.Equal(t, model.ProposalCommitted, committedProposal.Status)
+
+	response = invokeAs(t, secondIdentity, [][]byte{[]byte("GetSuperAdminByID"), []byte(secondSuperAdmin.SuperAdminID)})
+	var stateSuperAdmin model.SuperAdmin
+	json.Unmarshal([]byte(response), &stateSuperAdmin)
+	assert.Equal(t, secondSuperAdmin.PublicKey, stateSuperAdmin.PublicKey)
+	assert.Equal(t, "A", stateSuperAdmin.Status)
+}
+
+func TestRotateSuperAdminKey(t *testing.T) {
+	common.Logger.SetLevel(shim.LogDebug)
+
+	if stub == nil {
+		stub = setupMock(false)
+	}
+
+	newKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	// An inactive SuperAdmin can't rotate its key
+	inactiveKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	inactiveIdentity := newIdentity("Org1MSP", "inactivesuperadmin", "SuperAdmin")
+	inactiveMSPID, inactiveCertID := identityID(inactiveIdentity)
+	inactive, _ := json.Marshal(model.SuperAdmin{
+		SuperAdminID: "InactiveSuperAdmin",
+		PublicKey:    publicKeyPEM(&inactiveKey.PublicKey),
+		Status:       "I",
+		MSPID:        inactiveMSPID,
+		CertID:       inactiveCertID,
+	})
+	key, _ := stub.CreateCompositeKey(model.SuperAdminTable, []string{"InactiveSuperAdmin"})
+	stub.MockTransactionStart("inactive")
+	stub.PutState(key, inactive)
+	stub.MockTransactionEnd("inactive")
+	inactiveRotation, _ := json.Marshal(model.KeyRotation{SuperAdminID: "InactiveSuperAdmin", KeyVersion: 2, PublicKey: publicKeyPEM(&newKey.PublicKey)})
+	response := invokeAs(t, inactiveIdentity, [][]byte{[]byte("RotateSuperAdminKey"), inactiveRotation})
+	assert.Assert(t, strings.Contains(response, "This SuperAdmin is not active"), response)
+	stub.MockTransactionStart("inactive")
+	stub.DelState(key)
+	stub.MockTransactionEnd("inactive")
+
+	rotation := model.KeyRotation{SuperAdminID: superAdminID, KeyVersion: 2, PublicKey: publicKeyPEM(&newKey.PublicKey)}
+	rotationBytes, _ := json.Marshal(rotation)
+	response = invokeAs(t, superAdminIdentity, [][]byte{[]byte("GetKeyRotationPayload"), rotationBytes})
+	var payload map[string]string
+	json.Unmarshal([]byte(response), &payload)
+	data, _ := base64.StdEncoding.DecodeString(payload["Message"])
+
+	// The digest is made with the hash algorithm of the current key
+	digest := sha256.Sum256(data)
+	assert.Equal(t, base64.StdEncoding.EncodeToString(digest[:]), payload["Digest"])
+	assert.Equal(t, hUtil.HashSHA256, payload["HashAlgorithm"])
+
+	// The rotation must be signed by the current key
+	rotation.Signature = sign(newKey, data)
+	rotationBytes, _ = json.Marshal(rotation)
+	response = invokeAs(t, superAdminIdentity, [][]byte{[]byte("RotateSuperAdminKey"), rotationBytes})
+	assert.Assert(t, strings.Contains(response, common.ResCodeDict[common.ERR8]), response)
+
+	rotation.Signature = sign(superAdminKey, data)
+	rotationBytes, _ = json.Marshal(rotation)
+	response = invokeAs(t, superAdminIdentity, [][]byte{[]byte("RotateSuperAdminKey"), rotationBytes})
+	var rotatedSuperAdmin model.SuperAdmin
+	json.Unmarshal([]byte(response), &rotatedSuperAdmin)
+	assert.Equal(t, 2, rotatedSuperAdmin.KeyVersion)
+	assert.Equal(t, rotation.PublicKey, rotatedSuperAdmin.PublicKey)
+
+	// The previous key is kept with its validity window
+	assert.Equal(t, 2, len(rotatedSuperAdmin.Keys))
+	assert.Equal(t, publicKeyPEM(&superAdminKey.PublicKey), rotatedSuperAdmin.Keys[0].PublicKey)
+	assert.Assert(t, rotatedSuperAdmin.Keys[0].ValidUntil != "")
+	assert.Equal(t, rotatedSuperAdmin.Keys[0].ValidUntil, rotatedSuperAdmin.Keys[1].ValidFrom)
+	assert.Equal(t, "", rotatedSuperAdmin.Keys[1].ValidUntil)
+
+	// The signature can't be replayed
+	response = invokeAs(t, superAdminIdentity, [][]byte{[]byte("RotateSuperAdminKey"), rotationBytes})
+	assert.Assert(t, strings.Contains(response, "must be version 3"), response)
+	superAdminKey = newKey
+
+	// A lost key is replaced through a governance proposal
+	replacementKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	lostKeyRotation, _ := json.Marshal(model.KeyRotation{SuperAdminID: "SecondSuperAdmin", PublicKey: publicKeyPEM(&replacementKey.PublicKey)})
+	proposal, _ := json.Marshal(model.Proposal{CreatedBy: superAdminID, Message: "Replace a lost key", Type: model.ProposalRotateSuperAdminKey, Payload: string(lostKeyRotation)})
+	response = invokeAs(t, superAdminIdentity, [][]byte{[]byte("CreateGovernanceProposal"), proposal})
+	var createdProposal model.Proposal
+	json.Unmarshal([]byte(response), &createdProposal)
+
+	// The approval is verified with the new key
+	approval := approveProposal(t, createdProposal.ProposalID)
+	assert.Equal(t, 2, approval.KeyVersion)
+	invokeAs(t, adminIdentity, [][]byte{[]byte("CommitProposal"), []byte(createdProposal.ProposalID)})
+
+	response = invokeAs(t, superAdminIdentity, [][]byte{[]byte("GetSuperAdminByID"), []byte("SecondSuperAdmin")})
+	var stateSuperAdmin model.SuperAdmin
+	json.Unmarshal([]byte(response), &stateSuperAdmin)
+	assert.Equal(t, 2, stateSuperAdmin.KeyVersion)
+	assert.Equal(t, publicKeyPEM(&replacementKey.PublicKey), stateSuperAdmin.PublicKey)
+}
+
+func TestSuperAdminCredentials(t *testing.T) {
+	common.Logger.SetLevel(shim.LogDebug)
+
+	if stub == nil {
+		stub = setupMock(false)
+	}
+
+	// Enrol a backup YubiKey
+	backupKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	enrolment, _ := json.Marshal(model.CredentialEnrolment{
+		SuperAdminID: superAdminID,
+		Credential:   model.Credential{CredentialID: "backup", Label: "Backup YubiKey", PublicKey: publicKeyPEM(&backupKey.PublicKey)},
+	})
+	proposal, _ := json.Marshal(model.Proposal{CreatedBy: superAdminID, Message: "Backup key", Type: model.ProposalAddSuperAdminCredential, Payload: string(enrolment)})
+	response := invokeAs(t, superAdminIdentity, [][]byte{[]byte("CreateGovernanceProposal"), proposal})
+	var createdProposal model.Proposal
+	json.Unmarshal([]byte(response), &createdProposal)
+	approveProposal(t, createdProposal.ProposalID)
+	invokeAs(t, adminIdentity, [][]byte{[]byte("CommitProposal"), []byte(createdProposal.ProposalID)})
+
+	// The same key can't be enrolled for another SuperAdmin
+	enrolment, _ = json.Marshal(model.CredentialEnrolment{
+		SuperAdminID: "SecondSuperAdmin",
+		Credential:   model.Credential{CredentialID: "shared", PublicKey: publicKeyPEM(&backupKey.PublicKey)},
+	})
+	proposal, _ = json.Marshal(model.Proposal{CreatedBy: superAdminID, Type: model.ProposalAddSuperAdminCredential, Payload: string(enrolment)})
+	response = invokeAs(t, superAdminIdentity, [][]byte{[]byte("CreateGovernanceProposal"), proposal})
+	assert.Assert(t, strings.Contains(response, "already enrolled for SuperAdmin "+superAdminID), response)
+
+	// Approve with the backup key
+	proposal, _ = json.Marshal(model.Proposal{CreatedBy: adminID, Message: "Needs two approvals", QuorumNumber: 2})
+	response = invokeAs(t, adminIdentity, [][]byte{[]byte("CreateProposal"), proposal})
+	json.Unmarshal([]byte(response), &createdProposal)
+
+	challenge := requestChallenge(t, createdProposal.ProposalID)
+	response = invokeAs(t, superAdminIdentity, [][]byte{[]byte("GetApprovalPayload"), []byte(createdProposal.ProposalID), []byte(superAdminID), []byte("Approved")})
+	var payload map[string]string
+	json.Unmarshal([]byte(response), &payload)
+	data, _ := base64.StdEncoding.DecodeString(payload["Message"])
+	approvalBytes, _ := json.Marshal(model.Approval{
+		ProposalID:   createdProposal.ProposalID,
+		ApproverID:   superAdminID,
+		CredentialID: "backup",
+		Challenge:    challenge,
+		Signature:    sign(backupKey, data),
+		Status:       "Approved",
+	})
+	response = invokeAs(t, superAdminIdentity, [][]byte{[]byte("CreateApproval"), approvalBytes})
+	var approval model.Approval
+	json.Unmarshal([]byte(response), &approval)
+	assert.Equal(t, "backup", approval.CredentialID)
+	assert.Equal(t, 0, approval.KeyVersion)
+
+	// The person can't approve again with another key
+	requestChallenge(t, createdProposal.ProposalID)
+	_, signature := signApprovalPayload(t, createdProposal.ProposalID, "Approved")
+	approvalBytes, _ = json.Marshal(model.Approval{ProposalID: createdProposal.ProposalID, ApproverID: superAdminID, Signature: signature, Status: "Approved"})
+	response = invokeAs(t, superAdminIdentity, [][]byte{[]byte("CreateApproval"), approvalBytes})
+	var result map[string]interface{}
+	json.Unmarshal([]byte(response), &result)
+	assert.Equal(t, common.ERR4, result["status"])
+
+	// A revoked credential can't sign
+	response = invokeAs(t, superAdminIdentity, [][]byte{[]byte("RevokeSuperAdminCredential"), []byte(superAdminID), []byte("backup")})
+	var stateSuperAdmin model.SuperAdmin
+	json.Unmarshal([]byte(response), &stateSuperAdmin)
+	assert.Equal(t, model.CredentialRevoked, stateSuperAdmin.Credentials[0].Status)
+
+	proposal, _ = json.Marshal(model.Proposal{CreatedBy: adminID, Message: "After revocation", QuorumNumber: 1})
+	response = invokeAs(t, adminIdentity, [][]byte{[]byte("CreateProposal"), proposal})
+	json.Unmarshal([]byte(response), &createdProposal)
+	challenge = requestChallenge(t, createdProposal.ProposalID)
+	response = invokeAs(t, superAdminIdentity, [][]byte{[]byte("GetApprovalPayload"), []byte(createdProposal.ProposalID), []byte(superAdminID), []byte("Approved")})
+	json.Unmarshal([]byte(response), &payload)
+	data, _ = base64.StdEncoding.DecodeString(payload["Message"])
+	approvalBytes, _ = json.Marshal(model.Approval{
+		ProposalID:   createdProposal.ProposalID,
+		ApproverID:   superAdminID,
+		CredentialID: "backup",
+		Challenge:    challenge,
+		Signature:    sign(backupKey, data),
+		Status:       "Approved",
+	})
+	response = invokeAs(t, superAdminIdentity, [][]byte{[]byte("CreateApproval"), approvalBytes})
+	assert.Assert(t, strings.Contains(response, "The credential backup is Revoked"), response)
+}
+
+func TestWeightedQuorum(t *testing.T) {
+	common.Logger.SetLevel(shim.LogDebug)
+
+	if stub == nil {
+		stub = setupMock(false)
+	}
+
+	// A weight can't be negative
+	weight, _ := json.Marshal(map[string]interface{}{"SuperAdminID": superAdminID, "Weight": -1})
+	proposal, _ := json.Marshal(model.Proposal{CreatedBy: superAdminID, Type: model.ProposalUpdateSuperAdmin, Payload: string(weight)})
+	response := invokeAs(t, superAdminIdentity, [][]byte{[]byte("CreateGovernanceProposal"), proposal})
+	assert.Assert(t, strings.Contains(response, "can't be negative"), response)
+
+	// Give the SuperAdmin a weight of 3
+	weight, _ = json.Marshal(map[string]interface{}{"SuperAdminID": superAdminID, "Weight": 3})
+	proposal, _ = json.Marshal(model.Proposal{CreatedBy: superAdminID, Type: model.ProposalUpdateSuperAdmin, Payload: string(weight)})
+	response = invokeAs(t, superAdminIdentity, [][]byte{[]byte("CreateGovernanceProposal"), proposal})
+	var createdProposal model.Proposal
+	json.Unmarshal([]byte(response), &createdProposal)
+	approveProposal(t, createdProposal.ProposalID)
+	invokeAs(t, adminIdentity, [][]byte{[]byte("CommitProposal"), []byte(createdProposal.ProposalID)})
+
+	// The weight of the approval reaches the threshold
+	proposal, _ = json.Marshal(model.Proposal{CreatedBy: adminID, Message: "Weighted", QuorumNumber: 5, ThresholdWeight: 3})
+	response = invokeAs(t, adminIdentity, [][]byte{[]byte("CreateProposal"), proposal})
+	json.Unmarshal([]byte(response), &createdProposal)
+	approval := approveProposal(t, createdProposal.ProposalID)
+	assert.Equal(t, 3, approval.Weight)
+
+	response = invokeAs(t, adminIdentity, [][]byte{[]byte("GetProposalByID"), []byte(createdProposal.ProposalID)})
+	var stateProposal model.Proposal
+	json.Unmarshal([]byte(response), &stateProposal)
+	assert.Equal(t, model.ProposalApproved, stateProposal.Status)
+
+	// Without a threshold every approval counts as 1
+	proposal, _ = json.Marshal(model.Proposal{CreatedBy: adminID, Message: "Not weighted", QuorumNumber: 2})
+	response = invokeAs(t, adminIdentity, [][]byte{[]byte("CreateProposal"), proposal})
+	json.Unmarshal([]byte(response), &createdProposal)
+	approveProposal(t, createdProposal.ProposalID)
+
+	response = invokeAs(t, adminIdentity, [][]byte{[]byte("GetProposalByID"), []byte(createdProposal.ProposalID)})
+	json.Unmarshal([]byte(response), &stateProposal)
+	assert.Equal(t, model.ProposalPending, stateProposal.Status)
+}
+
+func TestApproverGroupPolicy(t *testing.T) {
+	common.Logger.SetLevel(shim.LogDebug)
+
+	if stub == nil {
+		stub = setupMock(false)
+	}
+
+	// Groups are created through governance
+	group, _ := json.Marshal(model.ApproverGroup{GroupID: "Finance", Name: "Finance", Members: []string{superAdminID, "Unknown"}})
+	proposal, _ := json.Marshal(model.Proposal{CreatedBy: superAdminID, Type: model.ProposalCreateApproverGroup, Payload: string(group)})
+	response := invokeAs(t, superAdminIdentity, [][]byte{[]byte("CreateGovernanceProposal"), proposal})
+	assert.Assert(t, strings.Contains(response, "Unknown SuperAdmin Unknown"), response)
+
+	group, _ = json.Marshal(model.ApproverGroup{GroupID: "Finance", Name: "Finance", Members: []string{superAdminID}})
+	proposal, _ = json.Marshal(model.Proposal{CreatedBy: superAdminID, Type: model.ProposalCreateApproverGroup, Payload: string(group)})
+	response = invokeAs(t, superAdminIdentity, [][]byte{[]byte("CreateGovernanceProposal"), proposal})
+	var createdProposal model.Proposal
+	json.Unmarshal([]byte(response), &createdProposal)
+	approveProposal(t, createdProposal.ProposalID)
+	invokeAs(t, adminIdentity, [][]byte{[]byte("CommitProposal"), []byte(createdProposal.ProposalID)})
+
+	response = invokeAs(t, auditorIdentity, [][]byte{[]byte("GetApproverGroupByID"), []byte("Finance")})
+	var stateGroup model.ApproverGroup
+	json.Unmarshal([]byte(response), &stateGroup)
+	assert.DeepEqual(t, []string{superAdminID}, stateGroup.Members)
+
+	// An Admin can't set a policy, SuperAdmins set it by updating the proposal
+	proposal, _ = json.Marshal(model.Proposal{CreatedBy: adminID, QuorumNumber: 1, Policy: "'" + superAdminID + "'"})
+	response = invokeAs(t, adminIdentity, [][]byte{[]byte("CreateProposal"), proposal})
+	assert.Assert(t, strings.Contains(response, "Not allowed to set the Policy"), response)
+
+	// setPolicy creates a proposal and sets its policy, returning the response of UpdateProposal
+	setPolicy := func(message string, policy string) string {
+		proposal, _ := json.Marshal(model.Proposal{CreatedBy: adminID, Message: message, QuorumNumber: 1})
+		response := invokeAs(t, adminIdentity, [][]byte{[]byte("CreateProposal"), proposal})
+		json.Unmarshal([]byte(response), &createdProposal)
+		update, _ := json.Marshal(map[string]string{"ProposalID": createdProposal.ProposalID, "Policy": policy})
+		return invokeAs(t, superAdminIdentity, [][]byte{[]byte("UpdateProposal"), update})
+	}
+
+	// The policy must parse and name existing groups, with enough members
+	for policy, message := range map[string]string{
+		"AND(Group(1, 'Finance')":                 "Expected , or )",
+		"Group(1, 'Security')":                    "Unknown approver group Security",
+		"Group(2, 'Finance')":                     "can't be reached by 1 members",
+		"OR(Group(1, 'Finance'), 'NoSuchPerson')": "Unknown SuperAdmin NoSuchPerson",
+	} {
+		response = setPolicy("Invalid", policy)
+		assert.Assert(t, strings.Contains(response, message), response)
+	}
+
+	// Both the Finance group and the second SuperAdmin must approve
+	response = setPolicy("AND", "AND(Group(1, 'Finance'), 'SecondSuperAdmin')")
+	var updatedProposal model.Proposal
+	assert.NilError(t, json.Unmarshal([]byte(response), &updatedProposal), response)
+	assert.Equal(t, "AND(Group(1, 'Finance'), 'SecondSuperAdmin')", updatedProposal.Policy)
+	approveProposal(t, createdProposal.ProposalID)
+
+	response = invokeAs(t, adminIdentity, [][]byte{[]byte("GetProposalByID"), []byte(createdProposal.ProposalID)})
+	var stateProposal model.Proposal
+	json.Unmarshal([]byte(response), &stateProposal)
+	assert.Equal(t, model.ProposalPending, stateProposal.Status)
+
+	// Either of them is enough
+	response = setPolicy("OR", "OR(Group(1, 'Finance'), 'SecondSuperAdmin')")
+	assert.NilError(t, json.Unmarshal([]byte(response), &updatedProposal), response)
+	approveProposal(t, createdProposal.ProposalID)
+
+	response = invokeAs(t, adminIdentity, [][]byte{[]byte("GetProposalByID"), []byte(createdProposal.ProposalID)})
+	json.Unmarshal([]byte(response), &stateProposal)
+	assert.Equal(t, model.ProposalApproved, stateProposal.Status)
+}
+
+func TestRejectionRules(t *testing.T) {
+	common.Logger.SetLevel(shim.LogDebug)
+
+	if stub == nil {
+		stub = setupMock(false)
+	}
+
+	// proposalStatus creates a proposal, votes on it as the SuperAdmin and returns its status
+	proposalStatus := func(proposal model.Proposal, status string) model.ProposalStatus {
+		proposal.CreatedBy = adminID
+		proposalBytes, _ := json.Marshal(proposal)
+		response := invokeAs(t, adminIdentity, [][]byte{[]byte("CreateProposal"), proposalBytes})
+		var createdProposal model.Proposal
+		json.Unmarshal([]byte(response), &createdProposal)
+		vote(t, createdProposal.ProposalID, status)
+
+		response = invokeAs(t, adminIdentity, [][]byte{[]byte("GetProposalByID"), []byte(createdProposal.ProposalID)})
+		var stateProposal model.Proposal
+		json.Unmarshal([]byte(response), &stateProposal)
+		return stateProposal.Status
+	}
+
+	for _, proposal := range []model.Proposal{
+		{QuorumNumber: 1, RejectionRule: "Maybe"},
+		{QuorumNumber: 1, RejectionRule: model.RejectionThreshold},
+		{QuorumNumber: 1, RejectionRule: model.RejectionVeto, RejectionCount: 2},
+	} {
+		proposal.CreatedBy = adminID
+		proposalBytes, _ := json.Marshal(proposal)
+		response := invokeAs(t, adminIdentity, [][]byte{[]byte("CreateProposal"), proposalBytes})
+		assert.Assert(t, strings.Contains(response, "ejection"), response)
+	}
+
+	// A single rejection vetoes the proposal by default
+	assert.Equal(t, model.ProposalRejected, proposalStatus(model.Proposal{QuorumNumber: 2}, model.ApprovalRejected))
+
+	// An abstention counts for neither side
+	assert.Equal(t, model.ProposalPending, proposalStatus(model.Proposal{QuorumNumber: 1}, model.ApprovalAbstain))
+
+	// The proposal needs 2 rejections
+	assert.Equal(t, model.ProposalPending, proposalStatus(model.Proposal{QuorumNumber: 2, RejectionRule: model.RejectionThreshold, RejectionCount: 2}, model.ApprovalRejected))
+
+	// The second SuperAdmin can still approve the proposal alone
+	assert.Equal(t, model.ProposalPending, proposalStatus(model.Proposal{QuorumNumber: 1, RejectionRule: model.RejectionImpossible}, model.ApprovalRejected))
+
+	// Without the SuperAdmin's approval the proposal can't reach 2 approvals
+	assert.Equal(t, model.ProposalRejected, proposalStatus(model.Proposal{QuorumNumber: 2, RejectionRule: model.RejectionImpossible}, model.ApprovalRejected))
+	assert.Equal(t, model.ProposalRejected, proposalStatus(model.Proposal{QuorumNumber: 2, RejectionRule: model.RejectionImpossible}, model.ApprovalAbstain))
+}
+
+func TestProposalQuorumStatus(t *testing.T) {
+	common.Logger.SetLevel(shim.LogDebug)
+
+	if stub == nil {
+		stub = setupMock(false)
+	}
+
+	// The quorum must be reachable by the 2 active SuperAdmins
+	for _, quorum := range []int{0, -1, 3} {
+		proposal, _ := json.Marshal(model.Proposal{CreatedBy: adminID, QuorumNumber: quorum})
+		response := invokeAs(t, adminIdentity, [][]byte{[]byte("CreateProposal"), proposal})
+		assert.Assert(t, strings.Contains(response, "QuorumNumber must be between 1 and 2"), response)
+	}
+
+	// The minimum quorum can't exceed the active SuperAdmins
+	response := invokeAs(t, adminIdentity, [][]byte{[]byte("GetConfig")})
+	var config model.Config
+	json.Unmarshal([]byte(response), &config)
+	assert.Equal(t, 1, config.MinQuorum)
+	config.MinQuorum = 3
+	configBytes, _ := json.Marshal(config)
+	proposal, _ := json.Marshal(model.Proposal{CreatedBy: superAdminID, Type: model.ProposalUpdateConfig, Payload: string(configBytes)})
+	response = invokeAs(t, superAdminIdentity, [][]byte{[]byte("CreateGovernanceProposal"), proposal})
+	assert.Assert(t, strings.Contains(response, "minimum quorum 3 can't be reached by 2"), response)
+
+	proposal, _ = json.Marshal(model.Proposal{CreatedBy: adminID, Message: "Two approvals", QuorumNumber: 2})
+	response = invokeAs(t, adminIdentity, [][]byte{[]byte("CreateProposal"), proposal})
+	var createdProposal model.Proposal
+	json.Unmarshal([]byte(response), &createdProposal)
+	approveProposal(t, createdProposal.ProposalID)
+
+	response = invokeAs(t, auditorIdentity, [][]byte{[]byte("GetProposalQuorumStatus"), []byte(createdProposal.ProposalID)})
+	var status model.QuorumStatus
+	json.Unmarshal([]byte(response), &status)
+	assert.Equal(t, model.ProposalPending, status.Status)
+	assert.Equal(t, 2, status.EligibleApprovers)
+	assert.Equal(t, 1, status.PendingApprovers)
+	assert.Equal(t, 1, status.Approvals)
+	assert.Equal(t, 2, status.Required)
+	assert.Equal(t, 1, status.RemainingApprovals)
+	assert.Assert(t, status.Reachable)
+}
+
+func TestEligibleApprovers(t *testing.T) {
+	common.Logger.SetLevel(shim.LogDebug)
+
+	if stub == nil {
+		stub = setupMock(false)
+	}
+
+	proposal, _ := json.Marshal(model.Proposal{CreatedBy: adminID, QuorumNumber: 1, EligibleApprovers: []string{"NoSuchPerson"}})
+	response := invokeAs(t, adminIdentity, [][]byte{[]byte("CreateProposal"), proposal})
+	assert.Assert(t, strings.Contains(response, "Unknown eligible approver NoSuchPerson"), response)
+
+	// The quorum must be reachable by the eligible approvers
+	proposal, _ = json.Marshal(model.Proposal{CreatedBy: adminID, QuorumNumber: 2, EligibleApprovers: []string{"SecondSuperAdmin"}})
+	response = invokeAs(t, adminIdentity, [][]byte{[]byte("CreateProposal"), proposal})
+	assert.Assert(t, strings.Contains(response, "QuorumNumber must be between 1 and 1"), response)
+
+	// Only the second SuperAdmin can vote
+	proposal, _ = json.Marshal(model.Proposal{CreatedBy: adminID, Message: "Second only", QuorumNumber: 1, EligibleApprovers: []string{"SecondSuperAdmin"}})
+	response = invokeAs(t, adminIdentity, [][]byte{[]byte("CreateProposal"), proposal})
+	var createdProposal model.Proposal
+	json.Unmarshal([]byte(response), &createdProposal)
+	response = invokeAs(t, superAdminIdentity, [][]byte{[]byte("RequestApprovalChallenge"), []byte(createdProposal.ProposalID), []byte(superAdminID)})
+	assert.Assert(t, strings.Contains(response, "not eligible"), response)
+
+	response = invokeAs(t, auditorIdentity, [][]byte{[]byte("GetProposalQuorumStatus"), []byte(createdProposal.ProposalID)})
+	var status model.QuorumStatus
+	json.Unmarshal([]byte(response), &status)
+	assert.Equal(t, 1, status.EligibleApprovers)
+
+	// The members of the Finance group can vote
+	proposal, _ = json.Marshal(model.Proposal{CreatedBy: adminID, Message: "Finance only", QuorumNumber: 1, EligibleGroups: []string{"Finance"}})
+	response = invokeAs(t, adminIdentity, [][]byte{[]byte("CreateProposal"), proposal})
+	json.Unmarshal([]byte(response), &createdProposal)
+	approval := approveProposal(t, createdProposal.ProposalID)
+	assert.Equal(t, createdProposal.ProposalID, approval.ProposalID)
+
+	response = invokeAs(t, adminIdentity, [][]byte{[]byte("GetProposalByID"), []byte(createdProposal.ProposalID)})
+	var stateProposal model.Proposal
+	json.Unmarshal([]byte(response), &stateProposal)
+	assert.Equal(t, model.ProposalApproved, stateProposal.Status)
+}
+
+func TestListPages(t *testing.T) {
+	common.Logger.SetLevel(shim.LogDebug)
+
+	if stub == nil {
+		stub = setupMock(false)
+	}
+
+	response := invokeAs(t, auditorIdentity, [][]byte{[]byte("GetProposalPage"), []byte("0"), []byte("")})
+	assert.Assert(t, strings.Contains(response, "The page size must be between 1 and 1000"), response)
+
+	response = invokeAs(t, auditorIdentity, [][]byte{[]byte("GetAllProposal")})
+	var allProposals []model.Proposal
+	json.Unmarshal([]byte(response), &allProposals)
+	assert.Assert(t, len(allProposals) > 2)
+
+	// Page through the proposals two at a time
+	seen := map[string]bool{}
+	bookmark := ""
+	for {
+		response = invokeAs(t, auditorIdentity, [][]byte{[]byte("GetProposalPage"), []byte("2"), []byte(bookmark)})
+		var page model.Page
+		json.Unmarshal([]byte(response), &page)
+		assert.Equal(t, int32(len(page.Records)), page.FetchedRecordsCount, response)
+		for _, record := range page.Records {
+			var proposal model.Proposal
+			json.Unmarshal(record, &proposal)
+			assert.Assert(t, !seen[proposal.ProposalID], proposal.ProposalID)
+			seen[proposal.ProposalID] = true
+		}
+		if page.FetchedRecordsCount < 2 {
+			break
+		}
+		bookmark = page.Bookmark
+	}
+	assert.Equal(t, len(allProposals), len(seen))
+
+	response = invokeAs(t, auditorIdentity, [][]byte{[]byte("GetSuperAdminPage"), []byte("10"), []byte("")})
+	var page model.Page
+	json.Unmarshal([]byte(response), &page)
+	var superAdmin model.SuperAdmin
+	json.Unmarshal(page.Records[0], &superAdmin)
+	assert.Assert(t, superAdmin.SuperAdminID != "", response)
+}
+
+func TestSearchProposals(t *testing.T) {
+	common.Logger.SetLevel(shim.LogDebug)
+
+	if stub == nil {
+		stub = setupMock(false)
+	}
+
+	for _, message := range []string{"Search: alpha", "search: beta", "Other"} {
+		proposal, _ := json.Marshal(model.Proposal{CreatedBy: adminID, Message: message, QuorumNumber: 1})
+		invokeAs(t, adminIdentity, [][]byte{[]byte("CreateProposal"), proposal})
+	}
+
+	search, _ := json.Marshal(model.ProposalSearch{
+		Status:        []model.ProposalStatus{model.ProposalPending},
+		CreatedBy:     adminID,
+		CreatedAfter:  "2000-01-01T00:00:00+07:00",
+		MessagePrefix: "SEARCH:",
+		Descending:    true,
+		PageSize:      10,
+	})
+	response := invokeAs(t, auditorIdentity, [][]byte{[]byte("SearchProposals"), search})
+	var page model.Page
+	json.Unmarshal([]byte(response), &page)
+	assert.Equal(t, int32(2), page.FetchedRecordsCount, response)
+	previous := ""
+	for _, record := range page.Records {
+		var proposal model.Proposal
+		json.Unmarshal(record, &proposal)
+		assert.Assert(t, strings.HasPrefix(strings.ToLower(proposal.Message), "search:"), proposal.Message)
+		assert.Equal(t, model.ProposalPending, proposal.Status)
+		assert.Assert(t, previous == "" || proposal.CreatedAt <= previous)
+		previous = proposal.CreatedAt
+	}
+
+	for search, message := range map[string]string{
+		`{"SortBy": "Message", "PageSize": 10}`:          "can only be sorted by CreatedAt or UpdatedAt",
+		`{"Status": ["Unknown"], "PageSize": 10}`:        "Unknown proposal status Unknown",
+		`{"UpdatedBefore": "yesterday", "PageSize": 10}`: "The UpdatedAt dates must be RFC3339",
+		`{"MessagePrefix": "Search", "PageSize": 0}`:     "The page size must be between 1 and 1000",
+	} {
+		response = invokeAs(t, auditorIdentity, [][]byte{[]byte("SearchProposals"), []byte(search)})
+		assert.Assert(t, strings.Contains(response, message), response)
+	}
+}
+
+func TestSecondaryIndexes(t *testing.T) {
+	common.Logger.SetLevel(shim.LogDebug)
+
+	if stub == nil {
+		stub = setupMock(false)
+	}
+
+	// Only the searches the indexes can't serve need CouchDB
+	executedQueries = map[string]bool{}
+	defer func() {
+		assert.Equal(t, 0, len(executedQueries), executedQueries)
+	}()
+
+	proposal, _ := json.Marshal(model.Proposal{CreatedBy: adminID, Message: "Indexed", QuorumNumber: 1})
+	response := invokeAs(t, adminIdentity, [][]byte{[]byte("CreateProposal"), proposal})
+	var createdProposal model.Proposal
+	json.Unmarshal([]byte(response), &createdProposal)
+	approvalID := approveProposal(t, createdProposal.ProposalID).ApprovalID
+	assert.Assert(t, approvalID != "")
+
+	response = invokeAs(t, auditorIdentity, [][]byte{[]byte("GetApprovalByID"), []byte(approvalID)})
+	var approval model.Approval
+	json.Unmarshal([]byte(response), &approval)
+	assert.Equal(t, approvalID, approval.ApprovalID, response)
+	assert.Equal(t, createdProposal.ProposalID, approval.ProposalID)
+
+	response = invokeAs(t, auditorIdentity, [][]byte{[]byte("GetApprovalBySuperAdminID"), []byte(approval.ApproverID)})
+	var approvals []model.Approval
+	json.Unmarshal([]byte(response), &approvals)
+	found := false
+	for _, a := range approvals {
+		assert.Equal(t, approval.ApproverID, a.ApproverID)
+		found = found || a.ApprovalID == approvalID
+	}
+	assert.Assert(t, found, response)
+
+	// A proposal is listed as pending until it expires
+	proposal, _ = json.Marshal(model.Proposal{CreatedBy: adminID, Message: "Short-lived", QuorumNumber: 1, TTL: 1})
+	response = invokeAs(t, adminIdentity, [][]byte{[]byte("CreateProposal"), proposal})
+	json.Unmarshal([]byte(response), &createdProposal)
+	response = invokeAs(t, auditorIdentity, [][]byte{[]byte("GetPendingProposalBySuperAdminID"), []byte(superAdminID)})
+	assert.Assert(t, strings.Contains(response, createdProposal.ProposalID), response)
+
+	expiresAt, _ := time.Parse(time.RFC3339, createdProposal.ExpiresAt)
+	response = invokeAt(t, superAdminIdentity, [][]byte{[]byte("ExpireProposals"), []byte("100")}, expiresAt.Add(time.Second))
+	assert.Assert(t, strings.Contains(response, createdProposal.ProposalID), response)
+	response = invokeAt(t, superAdminIdentity, [][]byte{[]byte("ExpireProposals"), []byte("100")}, expiresAt.Add(time.Second))
+	assert.Equal(t, "[]", response)
+
+	// Searches by CreatedBy or one Status are served by the indexes, a page at a time
+	for _, search := range []model.ProposalSearch{
+		{CreatedBy: adminID, Status: []model.ProposalStatus{model.ProposalPending, model.ProposalExpired}},
+		{Status: []model.ProposalStatus{model.ProposalExpired}, CreatedAfter: "2000-01-01T07:00:00+07:00"},
+	} {
+		search.PageSize = 2
+		proposalIDs := map[string]bool{}
+		previous := ""
+		for {
+			searchBytes, _ := json.Marshal(search)
+			response = invokeAs(t, auditorIdentity, [][]byte{[]byte("SearchProposals"), searchBytes})
+			var page model.Page
+			assert.NilError(t, json.Unmarshal([]byte(response), &page), response)
+			for _, record := range page.Records {
+				var proposal model.Proposal
+				json.Unmarshal(record, &proposal)
+				assert.Assert(t, !proposalIDs[proposal.ProposalID], proposal.ProposalID)
+				proposalIDs[proposal.ProposalID] = true
+				assert.Assert(t, search.CreatedBy == "" || proposal.CreatedBy == search.CreatedBy)
+				assert.Assert(t, proposal.Status == search.Status[0] || proposal.Status == search.Status[len(search.Status)-1], proposal.Status)
+				assert.Assert(t, previous <= proposal.CreatedAt)
+				previous = proposal.CreatedAt
+			}
+			if page.Bookmark == "" {
+				break
+			}
+			search.Bookmark = page.Bookmark
+		}
+		assert.Assert(t, proposalIDs[createdProposal.ProposalID])
+	}
+
+	for _, function := range []string{"GetSuperAdminPage", "GetAdminPage", "GetProposalPage", "GetApprovalPage", "GetApproverGroupPage"} {
+		response = invokeAs(t, auditorIdentity, [][]byte{[]byte(function), []byte("10"), []byte("")})
+		var page model.Page
+		assert.NilError(t, json.Unmarshal([]byte(response), &page), response)
+	}
+
+	// Approvals stored before the indexes are found again once they are rebuilt
+	key, _ := stub.CreateCompositeKey(model.ApprovalIDIndex, []string{approval.ApprovalID, approval.ProposalID, approval.ApproverID})
+	stub.MockTransactionStart("legacy")
+	stub.DelState(key)
+	stub.MockTransactionEnd("legacy")
+	response = invokeAs(t, auditorIdentity, [][]byte{[]byte("GetApprovalByID"), []byte(approvalID)})
+	assert.Assert(t, strings.Contains(response, "does not exist"), response)
+
+	response = invokeAs(t, auditorIdentity, [][]byte{[]byte("RebuildIndexes"), []byte("10"), []byte("")})
+	assert.Assert(t, strings.Contains(response, "doesn't contain role SuperAdmin"), response)
+	response = invokeAs(t, superAdminIdentity, [][]byte{[]byte("RebuildIndexes"), []byte("10"), []byte(key)})
+	assert.Assert(t, strings.Contains(response, "isn't a key of the proposals or approvals"), response)
+
+	// Each batch indexes a key range, the proposals then the approvals
+	rebuilt := model.IndexRebuild{}
+	startKey := ""
+	for batches := 1; ; batches++ {
+		response = invokeAs(t, superAdminIdentity, [][]byte{[]byte("RebuildIndexes"), []byte("10"), []byte(startKey)})
+		var rebuild model.IndexRebuild
+		assert.NilError(t, json.Unmarshal([]byte(response), &rebuild), response)
+		assert.Assert(t, rebuild.Proposals+rebuild.Approvals <= 10)
+		assert.Assert(t, rebuild.Proposals == 0 || rebuilt.Approvals == 0)
+		rebuilt.Proposals += rebuild.Proposals
+		rebuilt.Approvals += rebuild.Approvals
+		if rebuild.NextStartKey == "" {
+			assert.Assert(t, batches > 2, batches)
+			break
+		}
+		startKey = rebuild.NextStartKey
+	}
+	assert.Assert(t, rebuilt.Proposals > 10 && rebuilt.Approvals > 0, rebuilt)
+	response = invokeAs(t, auditorIdentity, [][]byte{[]byte("GetApprovalByID"), []byte(approvalID)})
+	json.Unmarshal([]byte(response), &approval)
+	assert.Equal(t, approvalID, approval.ApprovalID, response)
+}
+
+func TestBindSuperAdmins(t *testing.T) {
+	common.Logger.SetLevel(shim.LogDebug)
+
+	if stub == nil {
+		stub = setupMock(false)
+	}
+
+	// A SuperAdmin stored before the identity binding has no MSPID and CertID
+	legacyKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	legacyIdentity := newIdentity("Org1MSP", "legacysuperadmin", "SuperAdmin")
+	legacyMSPID, legacyCertID := identityID(legacyIdentity)
+	legacy, _ := json.Marshal(model.SuperAdmin{
+		SuperAdminID: "LegacySuperAdmin",
+		Name:         "Legacy SuperAdmin",
+		PublicKey:    publicKeyPEM(&legacyKey.PublicKey),
+		Status:       "A",
+	})
+	key, _ := stub.CreateCompositeKey(model.SuperAdminTable, []string{"LegacySuperAdmin"})
+	stub.MockTransactionStart("legacy")
+	stub.PutState(key, legacy)
+	stub.MockTransactionEnd("legacy")
+
+	rename, _ := json.Marshal(map[string]interface{}{"SuperAdminID": "LegacySuperAdmin", "Name": "Renamed SuperAdmin"})
+	proposal, _ := json.Marshal(model.Proposal{CreatedBy: "LegacySuperAdmin", Type: model.ProposalUpdateSuperAdmin, Payload: string(rename)})
+	response := invokeAs(t, legacyIdentity, [][]byte{[]byte("CreateGovernanceProposal"), proposal})
+	assert.Assert(t, strings.Contains(response, "can't act as SuperAdmin LegacySuperAdmin"), response)
+
+	for bindings, message := range map[string]string{
+		`[]`: "at least one SuperAdmin",
+		`[{"SuperAdminID":"LegacySuperAdmin","MSPID":"Org1MSP"}]`:                     "can't be empty",
+		`[{"SuperAdminID":"MissingSuperAdmin","MSPID":"Org1MSP","CertID":"cert"}]`:    "MissingSuperAdmin",
+		`[{"SuperAdminID":"` + superAdminID + `","MSPID":"Org1MSP","CertID":"cert"}]`: "already bound",
+	} {
+		response = util.MockInitTransaction(t, stub, [][]byte{[]byte("init"), []byte("BindSuperAdmins"), []byte(bindings)})
+		assert.Assert(t, strings.Contains(response, message), response)
+	}
+
+	// The upgrade binds the SuperAdmin, then it can act, but only once
+	bindings, _ := json.Marshal([]model.SuperAdminBinding{{SuperAdminID: "LegacySuperAdmin", MSPID: legacyMSPID, CertID: legacyCertID}})
+	response = util.MockInitTransaction(t, stub, [][]byte{[]byte("init"), []byte("BindSuperAdmins"), bindings})
+	assert.Equal(t, "", response)
+	response = invokeAs(t, legacyIdentity, [][]byte{[]byte("CreateGovernanceProposal"), proposal})
+	var createdProposal model.Proposal
+	json.Unmarshal([]byte(response), &createdProposal)
+	assert.Equal(t, model.ProposalPending, createdProposal.Status, response)
+	response = util.MockInitTransaction(t, stub, [][]byte{[]byte("init"), []byte("BindSuperAdmins"), bindings})
+	assert.Assert(t, strings.Contains(response, "already bound"), response)
+
+	stub.MockTransactionStart("legacy")
+	stub.DelState(key)
+	stub.MockTransactionEnd("legacy")
+}
@@ -8,4 +8,6 @@ type Admin struct {
 	AdminID string `json:"AdminID"`
 	Name    string `json:"Name"`
 	Status  string `json:"Status"`
+	MSPID   string `json:"MSPID"`  // MSP ID of the Admin's certificate, required
+	CertID  string `json:"CertID"` // ID of the Admin's certificate (cid.GetID), required
 }
@@ -3,14 +3,63 @@ package model
 // ApprovalTable - Table name
 const ApprovalTable = "HSTX_APPROVAL"
 
+// Secondary indexes of the approvals, their keys end with the key of the approval
+const (
+	// ApprovalIDIndex - keys: ApprovalID, ProposalID, ApproverID
+	ApprovalIDIndex = "HSTX_APPROVAL_ID"
+	// ApprovalApproverIndex - keys: ApproverID, ProposalID
+	ApprovalApproverIndex = "HSTX_APPROVAL_APPROVER"
+)
+
+// Decisions of an Approval
+const (
+	ApprovalApproved = "Approved"
+	ApprovalRejected = "Rejected"
+	// ApprovalAbstain - the approver votes neither for nor against the proposal
+	ApprovalAbstain = "Abstain"
+)
+
+// Signature formats of an Approval
+const (
+	// FormatRaw - the signature is a DER ECDSA signature over the ApprovalPayload
+	FormatRaw = "Raw"
+	// FormatWebAuthn - the signature is a WebAuthn assertion whose challenge is sha256(ApprovalPayload)
+	FormatWebAuthn = "WebAuthn"
+)
+
 // Approval contain a Super Admin's signature to Approve or Reject a Proposal
 type Approval struct {
 	ApprovalID string `json:"ApprovalID"`	// set
 	ProposalID string `json:"ProposalID"`	// args[0] proposalID
 	ApproverID string `json:"ApproverID"`	// args[0] approverID
-	Challenge  string `json:"Challenge"`	// args[0] singned challenge
+	Challenge  string `json:"Challenge"`	// set: nonce issued by RequestApprovalChallenge
 	Signature  string `json:"Signature"`	// args[0] signature
-	Message    string `json:"Message"`		// args[0] singned Message
-	Status     string `json:"Status"`		// args[0] approval status: Approved/Rejected
+	Message    string `json:"Message"`		// args[0] singned Message, base64 of the ApprovalPayload
+	Status     string `json:"Status"`		// args[0] approval status: Approved/Rejected/Abstain
+	Format     string `json:"Format"`		// args[0] signature format: Raw (default)/WebAuthn
+	AuthenticatorData string `json:"AuthenticatorData"`	// args[0] WebAuthn: base64 authenticator data
+	ClientDataJSON    string `json:"ClientDataJSON"`		// args[0] WebAuthn: base64 client data JSON
+	CredentialID string `json:"CredentialID"`	// args[0] optional: the approver's credential which signed, empty for its PublicKey
+	KeyVersion int    `json:"KeyVersion"`	// set: version of the approver's PublicKey which verified the signature, 0 for a credential
+	Weight     int    `json:"Weight"`		// set: vote weight of the approver when it signed
 	CreatedAt  string `json:"CreatedAt"`	// set
 }
+
+// ApprovalPayload is the canonical document signed by a Super Admin, its fields are serialized in this order
+type ApprovalPayload struct {
+	ProposalID        string   `json:"ProposalID"`
+	Message           string   `json:"Message"`
+	QuorumNumber      int      `json:"QuorumNumber"`
+	ThresholdWeight   int      `json:"ThresholdWeight,omitempty"`   // weighted proposals only
+	Policy            string   `json:"Policy,omitempty"`            // proposals with a policy only
+	RejectionRule     string   `json:"RejectionRule,omitempty"`     // proposals with a rejection rule only
+	RejectionCount    int      `json:"RejectionCount,omitempty"`    // Threshold rejection rule only
+	EligibleApprovers []string `json:"EligibleApprovers,omitempty"` // restricted proposals only
+	EligibleGroups    []string `json:"EligibleGroups,omitempty"`    // restricted proposals only
+	CreatedBy         string   `json:"CreatedBy"`
+	CreatedAt         string   `json:"CreatedAt"`
+	Type              string   `json:"Type,omitempty"`    // governance proposals only
+	Payload           string   `json:"Payload,omitempty"` // governance proposals only
+	Status            string   `json:"Status"`            // Approved/Rejected/Abstain
+	Challenge         string   `json:"Challenge"`         // nonce issued to the approver
+}
@@ -0,0 +1,16 @@
+package model
+
+// ApproverGroupTable - Table name
+const ApproverGroupTable = "HSTX_APPROVER_GROUP"
+
+// AllSuperAdmins is the GroupID of every active SuperAdmin in a policy
+const AllSuperAdmins = "*"
+
+// ApproverGroup is a named set of SuperAdmins a proposal's Policy can require approvals from
+type ApproverGroup struct {
+	GroupID   string   `json:"GroupID"`   // args[0]
+	Name      string   `json:"Name"`      // args[0]
+	Members   []string `json:"Members"`   // args[0] SuperAdminIDs
+	CreatedAt string   `json:"CreatedAt"` // set
+	UpdatedAt string   `json:"UpdatedAt"` // set
+}
@@ -0,0 +1,13 @@
+package model
+
+// ChallengeTable - Table name
+const ChallengeTable = "HSTX_CHALLENGE"
+
+// Challenge is a one-time nonce issued to a Super Admin, to include in the signed payload of their Approval
+type Challenge struct {
+	ProposalID string `json:"ProposalID"` // args[0]
+	ApproverID string `json:"ApproverID"` // args[1]
+	Nonce      string `json:"Nonce"`      // set: derived from the transaction
+	CreatedAt  string `json:"CreatedAt"`  // set
+	ExpiresAt  string `json:"ExpiresAt"`  // set
+}
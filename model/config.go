@@ -0,0 +1,15 @@
+package model
+
+// ConfigTable - Table name
+const ConfigTable = "HSTX_CONFIG"
+
+// ConfigID is the key of the single Config row
+const ConfigID = "CONFIG"
+
+// Config holds the settings of the chaincode stored on the ledger
+type Config struct {
+	MaxProposalTTL     int64 `json:"MaxProposalTTL"`     // args[0] longest lifetime of a proposal, in seconds
+	DefaultProposalTTL int64 `json:"DefaultProposalTTL"` // args[0] lifetime of a proposal created without TTL, in seconds
+	GovernanceQuorum   int   `json:"GovernanceQuorum"`   // args[0] approvals needed by a governance proposal, 0 means a majority of the active SuperAdmins
+	MinQuorum          int   `json:"MinQuorum"`          // args[0] smallest QuorumNumber or ThresholdWeight of a proposal
+}
@@ -0,0 +1,27 @@
+package model
+
+// Statuses of a Credential, a revoked credential is kept to re-verify the approvals it signed
+const (
+	CredentialActive  = "Active"
+	CredentialRevoked = "Revoked"
+)
+
+// Credential is an authenticator of a SuperAdmin besides its PublicKey, e.g. a backup YubiKey.
+// Any active credential can sign the SuperAdmin's approvals.
+type Credential struct {
+	CredentialID    string `json:"CredentialID"`    // args[0] key handle of the authenticator, unique for the SuperAdmin
+	Label           string `json:"Label"`           // args[0] e.g. "Backup YubiKey"
+	PublicKey       string `json:"PublicKey"`       // args[0] format: pem
+	SignatureScheme string `json:"SignatureScheme"` // args[0] optional, detected from PublicKey
+	HashAlgorithm   string `json:"HashAlgorithm"`   // args[0] optional: SHA256 (default)/SHA384/SHA512, empty for ED25519
+	Status          string `json:"Status"`          // set: Active/Revoked
+	SignCount       uint32 `json:"SignCount"`       // set: last WebAuthn signature counter
+	AddedAt         string `json:"AddedAt"`         // set: UTC RFC3339
+	RevokedAt       string `json:"RevokedAt"`       // set by RevokeSuperAdminCredential
+}
+
+// CredentialEnrolment adds a Credential to a SuperAdmin, it is the payload of an AddSuperAdminCredential proposal
+type CredentialEnrolment struct {
+	SuperAdminID string     `json:"SuperAdminID"`
+	Credential   Credential `json:"Credential"`
+}
@@ -0,0 +1,14 @@
+package model
+
+// Genesis is the argument of Init, it enrols the first SuperAdmins and sets the governance quorum
+type Genesis struct {
+	SuperAdmins      []SuperAdmin `json:"SuperAdmins"`      // args[0] the first SuperAdmins, their MSPID and CertID are required
+	GovernanceQuorum int          `json:"GovernanceQuorum"` // args[0] approvals needed by a governance proposal
+}
+
+// SuperAdminBinding binds a SuperAdmin created before the identity binding to its identity, see BindSuperAdmins
+type SuperAdminBinding struct {
+	SuperAdminID string `json:"SuperAdminID"` // args[0] a SuperAdmin without MSPID and CertID
+	MSPID        string `json:"MSPID"`        // args[0] MSP ID of the SuperAdmin's certificate
+	CertID       string `json:"CertID"`       // args[0] ID of the SuperAdmin's certificate (cid.GetID)
+}
@@ -0,0 +1,8 @@
+package model
+
+// IndexRebuild is the result of a RebuildIndexes batch
+type IndexRebuild struct {
+	Proposals    int    `json:"Proposals"`    // proposals indexed by the batch
+	Approvals    int    `json:"Approvals"`    // approvals indexed by the batch
+	NextStartKey string `json:"NextStartKey"` // pass it back to index the next batch, empty once every record is indexed
+}
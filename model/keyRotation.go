@@ -0,0 +1,21 @@
+package model
+
+// KeyRotation replaces the key of a SuperAdmin
+type KeyRotation struct {
+	SuperAdminID    string `json:"SuperAdminID"`    // args[0]
+	KeyVersion      int    `json:"KeyVersion"`      // args[0] optional: version of the new key, the current version + 1
+	PublicKey       string `json:"PublicKey"`       // args[0] new key (format: pem)
+	SignatureScheme string `json:"SignatureScheme"` // args[0] optional, detected from PublicKey
+	HashAlgorithm   string `json:"HashAlgorithm"`   // args[0] optional: SHA256 (default)/SHA384/SHA512, empty for ED25519
+	Signature       string `json:"Signature"`       // args[0] base64 signature of the KeyRotationPayload by the current key, empty in a RotateSuperAdminKey proposal
+}
+
+// KeyRotationPayload is the canonical document signed by the current key of a SuperAdmin to hand over to a new key,
+// its fields are serialized in this order
+type KeyRotationPayload struct {
+	SuperAdminID    string `json:"SuperAdminID"`
+	KeyVersion      int    `json:"KeyVersion"`
+	PublicKey       string `json:"PublicKey"`
+	SignatureScheme string `json:"SignatureScheme"`
+	HashAlgorithm   string `json:"HashAlgorithm"`
+}
@@ -0,0 +1,13 @@
+package model
+
+import "encoding/json"
+
+// MaxPageSize is the largest page a paginated list query returns
+const MaxPageSize = 1000
+
+// Page is a page of the records returned by a paginated list query
+type Page struct {
+	Records             []json.RawMessage `json:"Records"`
+	Bookmark            string            `json:"Bookmark"`            // pass it back to get the next page
+	FetchedRecordsCount int32             `json:"FetchedRecordsCount"` // fewer than the page size on the last page
+}
@@ -3,13 +3,80 @@ package model
 // ProposalTable - Table name
 const ProposalTable = "HSTX_PROPOSAL"
 
+// Secondary indexes of the proposals, their keys end with the ProposalID
+const (
+	// ProposalStatusIndex - keys: Status, CreatedAt, ProposalID
+	ProposalStatusIndex = "HSTX_PROPOSAL_STATUS"
+	// ProposalCreatorIndex - keys: CreatedBy, CreatedAt, ProposalID
+	ProposalCreatorIndex = "HSTX_PROPOSAL_CREATOR"
+	// ProposalExpiryIndex - keys: ExpiresAt, ProposalID, only for Pending and Approved proposals
+	ProposalExpiryIndex = "HSTX_PROPOSAL_EXPIRY"
+)
+
+// ProposalStatus is the state of a Proposal, it only changes through the allowed transitions
+type ProposalStatus string
+
+// States of a Proposal
+const (
+	ProposalPending   ProposalStatus = "Pending"
+	ProposalApproved  ProposalStatus = "Approved"
+	ProposalRejected  ProposalStatus = "Rejected"
+	ProposalCommitted ProposalStatus = "Committed"
+	ProposalCancelled ProposalStatus = "Cancelled"
+	ProposalExpired   ProposalStatus = "Expired"
+)
+
+// Rejection rules of a Proposal
+const (
+	// RejectionVeto - a single rejection rejects the proposal
+	RejectionVeto = "Veto"
+	// RejectionThreshold - RejectionCount rejections reject the proposal
+	RejectionThreshold = "Threshold"
+	// RejectionImpossible - the proposal is rejected once the SuperAdmins who haven't voted can't approve it any more
+	RejectionImpossible = "Impossible"
+)
+
+// Types of governance proposals, they change the SuperAdmins or the Config when they are committed
+const (
+	ProposalAddSuperAdmin           = "AddSuperAdmin"
+	ProposalUpdateSuperAdmin        = "UpdateSuperAdmin"
+	ProposalRotateSuperAdminKey     = "RotateSuperAdminKey"
+	ProposalAddSuperAdminCredential = "AddSuperAdminCredential"
+	ProposalCreateApproverGroup     = "CreateApproverGroup"
+	ProposalUpdateApproverGroup     = "UpdateApproverGroup"
+	ProposalUpdateConfig            = "UpdateConfig"
+)
+
+// Transition records a change of a Proposal's status
+type Transition struct {
+	From      ProposalStatus `json:"From"`      // empty when the proposal is created
+	To        ProposalStatus `json:"To"`
+	MSPID     string         `json:"MSPID"`     // MSP ID of the caller
+	Actor     string         `json:"Actor"`     // Certificate ID of the caller
+	Timestamp string         `json:"Timestamp"` // transaction time, UTC RFC3339
+}
+
 // Proposal - struct
 type Proposal struct {
 	ProposalID 		 string `json:"ProposalID"`			// set
 	Message    		 string `json:"Message"`   			// args[0]
 	CreatedBy  		 string `json:"CreatedBy"` 			// args[0]: ID of Admin/SAdmin
-	Status     		 string `json:"Status"`    			// set
-	QuorumNumber     int 	`json:"QuorumNumber"`		// args[0]
+	Status     		 ProposalStatus `json:"Status"`    	// set
+	QuorumNumber     int 	`json:"QuorumNumber"`		// args[0], set to the governance quorum for a governance proposal
+	ThresholdWeight  int 	`json:"ThresholdWeight"`	// args[0] optional: total weight of approvals needed instead of QuorumNumber, 0 counts each approval as 1
+	RejectionRule 	 string `json:"RejectionRule"`  	// args[0] optional: Veto (default)/Threshold/Impossible
+	RejectionCount 	 int 	`json:"RejectionCount"`  	// args[0] Threshold: number of rejections which reject the proposal
+	EligibleApprovers []string `json:"EligibleApprovers"`	// args[0] optional: SuperAdminIDs who can vote, with the members of EligibleGroups; anyone if both are empty
+	EligibleGroups 	 []string `json:"EligibleGroups"`  	// args[0] optional: GroupIDs whose members can vote
+	Policy 		     string `json:"Policy"`  		// args[0] optional: policy expression the approvals must satisfy instead of QuorumNumber, e.g. AND(Group(2, 'Finance'), Group(1, 'Security'))
+	Type 		     string `json:"Type"`  			// args[0] empty, or the type of a governance proposal: AddSuperAdmin/UpdateSuperAdmin/RotateSuperAdminKey/AddSuperAdminCredential/CreateApproverGroup/UpdateApproverGroup/UpdateConfig
+	Payload 	     string `json:"Payload"`  		// args[0] governance: JSON document applied when the proposal is committed
 	CreatedAt 	     string `json:"CreatedAt"`  		// args[0]
 	UpdatedAt 	     string `json:"UpdatedAt"`  		// args[0]
+	TTL 		     int64  `json:"TTL"`  			// args[0] optional: lifetime in seconds, bounded by Config.MaxProposalTTL
+	ExpiresAt 	     string `json:"ExpiresAt"`  		// set: CreatedAt + TTL
+	CreatorMSPID 	 string `json:"CreatorMSPID"`  		// set: MSP ID of the caller of CreateProposal
+	CreatorCertID 	 string `json:"CreatorCertID"`  	// set: Certificate ID of the caller of CreateProposal
+	CancelReason 	 string `json:"CancelReason"`  		// set by CancelProposal
+	Transitions 	 []Transition `json:"Transitions"`  	// set: history of the status
 }
@@ -0,0 +1,22 @@
+package model
+
+// Fields a proposal search can be sorted by
+const (
+	SortByCreatedAt = "CreatedAt"
+	SortByUpdatedAt = "UpdatedAt"
+)
+
+// ProposalSearch is the filter of SearchProposals, its empty fields don't filter
+type ProposalSearch struct {
+	Status        []ProposalStatus `json:"Status"`        // one of these statuses
+	CreatedBy     string           `json:"CreatedBy"`     // ID of the Admin/SAdmin
+	CreatedAfter  string           `json:"CreatedAfter"`  // RFC3339, inclusive
+	CreatedBefore string           `json:"CreatedBefore"` // RFC3339, exclusive
+	UpdatedAfter  string           `json:"UpdatedAfter"`  // RFC3339, inclusive
+	UpdatedBefore string           `json:"UpdatedBefore"` // RFC3339, exclusive
+	MessagePrefix string           `json:"MessagePrefix"` // start of the Message, ignoring case
+	SortBy        string           `json:"SortBy"`        // CreatedAt (default)/UpdatedAt
+	Descending    bool             `json:"Descending"`    // newest first
+	PageSize      int              `json:"PageSize"`      // 1 to MaxPageSize
+	Bookmark      string           `json:"Bookmark"`      // empty for the first page
+}
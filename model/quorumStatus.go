@@ -0,0 +1,16 @@
+package model
+
+// QuorumStatus reports how far the votes on a Proposal are from approving it
+type QuorumStatus struct {
+	ProposalID         string         `json:"ProposalID"`
+	Status             ProposalStatus `json:"Status"`
+	EligibleApprovers  int            `json:"EligibleApprovers"` // active SuperAdmins who can vote on the proposal
+	PendingApprovers   int            `json:"PendingApprovers"`  // active SuperAdmins who haven't voted
+	Approvals          int            `json:"Approvals"`
+	Rejections         int            `json:"Rejections"`
+	Abstentions        int            `json:"Abstentions"`
+	Required           int            `json:"Required"`           // QuorumNumber or ThresholdWeight, 0 for a policy
+	ApprovedWeight     int            `json:"ApprovedWeight"`     // what the approvals add up to against Required
+	RemainingApprovals int            `json:"RemainingApprovals"` // approvals still needed, an upper bound for a policy naming a SuperAdmin twice
+	Reachable          bool           `json:"Reachable"`          // false if the pending approvers can't approve the proposal any more
+}
@@ -5,8 +5,29 @@ const SuperAdminTable = "HSTX_SUPER_ADMIN"
 
 // SuperAdmin , who has permission to approve or reject a proposal, is a member in the Quorum
 type SuperAdmin struct {
-	SuperAdminID string `json:"SuperAdminID"`	// args[0] keyhandle of yubikey and application
+	SuperAdminID string `json:"SuperAdminID"`	// args[0] keyhandle of yubikey and application, the ID of the person whatever credential signs
 	Name         string `json:"Name"`			// args[0] name
 	PublicKey    string `json:"PublicKey"`		// args[0] publickey of yubikey (format: pem)
+	SignatureScheme string `json:"SignatureScheme"`	// args[0] optional, detected from PublicKey: ECDSA_P256/ECDSA_P384/ECDSA_SECP256K1/ED25519/RSA_PKCS1V15/RSA_PSS
+	HashAlgorithm   string `json:"HashAlgorithm"`	// args[0] optional: SHA256 (default)/SHA384/SHA512, empty for ED25519
 	Status       string `json:"Status"`			// args[0] A/I (active/inactive)
+	Weight       int    `json:"Weight"`		// args[0] optional: vote weight in proposals with a ThresholdWeight, 0 counts as 1
+	RpID         string `json:"RpID"`			// args[0] WebAuthn relying party ID the yubikey is registered with
+	RequireUserVerification bool `json:"RequireUserVerification"`	// args[0] WebAuthn: require PIN or biometrics
+	SignCount    uint32 `json:"SignCount"`		// set: last WebAuthn signature counter
+	MSPID        string `json:"MSPID"`			// args[0] MSP ID of the SuperAdmin's certificate, required
+	CertID       string `json:"CertID"`		// args[0] ID of the SuperAdmin's certificate (cid.GetID), required
+	KeyVersion   int    `json:"KeyVersion"`	// set: version of PublicKey
+	Keys         []SuperAdminKey `json:"Keys"`	// set: every key of the SuperAdmin, the last one is PublicKey
+	Credentials  []Credential `json:"Credentials"`	// args[0] optional: other authenticators of the SuperAdmin
+}
+
+// SuperAdminKey is a version of a SuperAdmin's key, kept to re-verify the approvals signed with it
+type SuperAdminKey struct {
+	KeyVersion      int    `json:"KeyVersion"`
+	PublicKey       string `json:"PublicKey"`
+	SignatureScheme string `json:"SignatureScheme"`
+	HashAlgorithm   string `json:"HashAlgorithm"`
+	ValidFrom       string `json:"ValidFrom"`  // UTC RFC3339, empty for a key enrolled before the history was kept
+	ValidUntil      string `json:"ValidUntil"` // UTC RFC3339, empty for the current key
 }
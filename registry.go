@@ -0,0 +1,175 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/Akachain/akc-go-sdk/common"
+	hUtil "github.com/Akachain/hstx-go-sdk/utils"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+// argType describes how an argument is checked before the function is dispatched
+type argType string
+
+const (
+	// argString accepts any string, usually an ID
+	argString argType = "string"
+	// argJSON accepts a JSON document which is decoded by the handler
+	argJSON argType = "json"
+	// argInt accepts a base 10 integer
+	argInt argType = "int"
+)
+
+// argSpec describes a positional argument of a chaincode function
+type argSpec struct {
+	Name string  `json:"Name"`
+	Type argType `json:"Type"`
+}
+
+// chaincodeFunction is a function callable through Invoke or Query
+type chaincodeFunction struct {
+	Name     string    `json:"Name"`
+	Args     []argSpec `json:"Args"`
+	ReadOnly bool      `json:"ReadOnly"` // read-only functions are also callable through Query
+	Roles    []string  `json:"Roles"`    // the caller must own one of these roles, empty means anyone
+	call     func(stub shim.ChaincodeStubInterface, args []string) (*string, error)
+}
+
+// registry holds every chaincode function, each registered once
+type registry struct {
+	functions map[string]*chaincodeFunction
+	names     []string // registration order, used to list the functions
+}
+
+func newRegistry() *registry {
+	return &registry{functions: make(map[string]*chaincodeFunction)}
+}
+
+// register adds a function to the registry. It panics on programming errors
+// because the registry is built once when the chaincode starts.
+func (r *registry) register(f chaincodeFunction) {
+	if f.call == nil {
+		panic(fmt.Sprintf("chaincode function %s has no implementation", f.Name))
+	}
+	if _, ok := r.functions[f.Name]; ok {
+		panic(fmt.Sprintf("chaincode function %s is registered twice", f.Name))
+	}
+	r.functions[f.Name] = &f
+	r.names = append(r.names, f.Name)
+}
+
+// dispatch validates the arguments and the caller's role, calls the function and wraps its result.
+// If readOnly is true, only read-only functions can be called.
+func (r *registry) dispatch(stub shim.ChaincodeStubInterface, functionName string, args []string, readOnly bool) pb.Response {
+	f, ok := r.functions[functionName]
+	if !ok || (readOnly && !f.ReadOnly) {
+		// Returning error: Unknown function
+		return common.RespondError(common.ResponseError{
+			ResCode: common.ERR2,
+			Msg:     fmt.Sprintf("Unknown function '%s' %s", functionName, common.GetLine()),
+		})
+	}
+
+	err := f.checkArgs(args)
+	if err != nil {
+		// Returning error: Invalid arguments
+		return common.RespondError(common.ResponseError{
+			ResCode: common.ERR2,
+			Msg:     fmt.Sprintf("%s %s %s", common.ResCodeDict[common.ERR2], err.Error(), common.GetLine()),
+		})
+	}
+
+	if len(f.Roles) > 0 {
+		err = hUtil.HasRole(stub, f.Roles...)
+		if err != nil {
+			// Returning error: Permission denied
+			return common.RespondError(common.ResponseError{
+				ResCode: common.ERR4,
+				Msg:     fmt.Sprintf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine()),
+			})
+		}
+	}
+
+	result, err := f.call(stub, args)
+	if err != nil {
+		// Returning error: Handler failed
+		return common.RespondError(common.ResponseError{
+			ResCode: common.ERR4,
+			Msg:     fmt.Sprintf("%s %s %s", common.ResCodeDict[common.ERR4], err.Error(), common.GetLine()),
+		})
+	}
+
+	// Returning success with payload is the handler's result
+	resSuc := common.ResponseSuccess{
+		ResCode: common.SUCCESS,
+		Msg:     common.ResCodeDict[common.SUCCESS],
+		Payload: *result,
+	}
+	return common.RespondSuccess(resSuc)
+}
+
+// checkArgs validates the arguments against the function's argument schema
+func (f *chaincodeFunction) checkArgs(args []string) error {
+	if len(args) != len(f.Args) {
+		return fmt.Errorf("%s expects %d arguments, but got %d", f.Name, len(f.Args), len(args))
+	}
+	for i, spec := range f.Args {
+		switch spec.Type {
+		case argJSON:
+			if !json.Valid([]byte(args[i])) {
+				return fmt.Errorf("argument %s must be a JSON document", spec.Name)
+			}
+		case argInt:
+			if _, err := strconv.Atoi(args[i]); err != nil {
+				return fmt.Errorf("argument %s must be an integer", spec.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// describe returns the list of registered functions as JSON
+func (r *registry) describe(stub shim.ChaincodeStubInterface) (*string, error) {
+	list := make([]*chaincodeFunction, 0, len(r.names))
+	for _, name := range r.names {
+		list = append(list, r.functions[name])
+	}
+
+	bytes, err := json.Marshal(list)
+	if err != nil { // Return error: Can't marshal json
+		return nil, fmt.Errorf("%s %s %s", common.ResCodeDict[common.ERR3], err.Error(), common.GetLine())
+	}
+	result := string(bytes)
+	return &result, nil
+}
+
+// withoutArgs adapts a handler method that takes no argument
+func withoutArgs(f func(shim.ChaincodeStubInterface) (*string, error)) func(shim.ChaincodeStubInterface, []string) (*string, error) {
+	return func(stub shim.ChaincodeStubInterface, args []string) (*string, error) {
+		return f(stub)
+	}
+}
+
+// withStringArg adapts a handler method that takes a single string argument, an ID or a JSON document
+func withStringArg(f func(shim.ChaincodeStubInterface, string) (*string, error)) func(shim.ChaincodeStubInterface, []string) (*string, error) {
+	return func(stub shim.ChaincodeStubInterface, args []string) (*string, error) {
+		return f(stub, args[0])
+	}
+}
+
+// withTwoStringArgs adapts a handler method that takes two string arguments
+func withTwoStringArgs(f func(shim.ChaincodeStubInterface, string, string) (*string, error)) func(shim.ChaincodeStubInterface, []string) (*string, error) {
+	return func(stub shim.ChaincodeStubInterface, args []string) (*string, error) {
+		return f(stub, args[0], args[1])
+	}
+}
+
+// withThreeStringArgs adapts a handler method that takes three string arguments
+func withThreeStringArgs(f func(shim.ChaincodeStubInterface, string, string, string) (*string, error)) func(shim.ChaincodeStubInterface, []string) (*string, error) {
+	return func(stub shim.ChaincodeStubInterface, args []string) (*string, error) {
+		return f(stub, args[0], args[1], args[2])
+	}
+}
@@ -0,0 +1,134 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// IndexDir is where the chaincode package ships its CouchDB indexes, relative to the chaincode directory
+const IndexDir = "META-INF/statedb/couchdb/indexes"
+
+// Index is a CouchDB index definition
+type Index struct {
+	Index struct {
+		Fields []string `json:"fields"`
+	} `json:"index"`
+	Ddoc string `json:"ddoc"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// LoadIndexes reads the index definitions of a directory
+func LoadIndexes(dir string) ([]*Index, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	indexes := []*Index{}
+	for _, file := range files {
+		bytes, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		index := new(Index)
+		err = json.Unmarshal(bytes, index)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", file, err.Error())
+		}
+		if len(index.Index.Fields) == 0 || index.Ddoc == "" || index.Name == "" || index.Type != "json" {
+			return nil, fmt.Errorf("%s: an index needs fields, a ddoc, a name and the json type", file)
+		}
+		indexes = append(indexes, index)
+	}
+	return indexes, nil
+}
+
+// Serves reports whether CouchDB can use the index for the query: every field of the index is in the selector,
+// and the sort fields start the index in the same order
+func (index *Index) Serves(query *Query) bool {
+	fields := selectorFields(query.Selector)
+	for _, field := range index.Index.Fields {
+		if !fields[field] {
+			return false
+		}
+	}
+	if len(query.Sort) > len(index.Index.Fields) {
+		return false
+	}
+	for i, sort := range query.Sort {
+		if _, ok := sort[index.Index.Fields[i]]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckIndexed returns an error unless one of the indexes serves the query, the one in use_index if it is set,
+// or one of its design document if use_index only names the design document.
+// The _id selector of a table doesn't count, CouchDB can't use its primary index for a regular expression.
+func CheckIndexed(query *Query, indexes []*Index) error {
+	for _, index := range indexes {
+		if !query.usesIndex(index) {
+			continue
+		}
+		if index.Serves(query) {
+			return nil
+		}
+	}
+	if len(query.UseIndex) > 0 {
+		return fmt.Errorf("The index %s can't serve the query", strings.Join(query.UseIndex, "/"))
+	}
+	return fmt.Errorf("No index serves the query")
+}
+
+// usesIndex func to check whether use_index lets CouchDB pick the index, use_index is ["ddoc"] or ["ddoc", "name"]
+func (query *Query) usesIndex(index *Index) bool {
+	switch len(query.UseIndex) {
+	case 0:
+		return true
+	case 1:
+		return query.UseIndex[0] == index.Ddoc
+	}
+	return query.UseIndex[0] == index.Ddoc && query.UseIndex[1] == index.Name
+}
+
+// selectorFields func to list the fields a selector constrains for every document, the ones of $and included
+func selectorFields(selector map[string]interface{}) map[string]bool {
+	fields := map[string]bool{}
+	for field, condition := range selector {
+		switch field {
+		case "$and":
+			for _, child := range andSelectors(condition) {
+				for childField := range selectorFields(child) {
+					fields[childField] = true
+				}
+			}
+		case "_id", "$or", "$nor", "$not":
+		default:
+			fields[field] = true
+		}
+	}
+	return fields
+}
+
+// andSelectors func to get the selectors of $and, built by And or decoded from JSON
+func andSelectors(condition interface{}) []map[string]interface{} {
+	selectors := []map[string]interface{}{}
+	switch children := condition.(type) {
+	case []Selector:
+		for _, child := range children {
+			selectors = append(selectors, child)
+		}
+	case []interface{}:
+		for _, child := range children {
+			if selector, ok := child.(map[string]interface{}); ok {
+				selectors = append(selectors, selector)
+			}
+		}
+	}
+	return selectors
+}
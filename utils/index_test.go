@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"encoding/json"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestCheckIndexed(t *testing.T) {
+	indexes, err := LoadIndexes("../" + IndexDir)
+	assert.NilError(t, err)
+	assert.Assert(t, len(indexes) > 0)
+
+	index := &Index{Ddoc: "indexStatusExpiresAtDoc", Name: "indexStatusExpiresAt", Type: "json"}
+	index.Index.Fields = []string{"Status", "ExpiresAt"}
+	statusExpiresAt := []*Index{index}
+
+	assert.NilError(t, CheckIndexed(NewQuery(InTable("T"), In("Status", "a"), Between("ExpiresAt", "", "b")), statusExpiresAt))
+	assert.NilError(t, CheckIndexed(NewQuery(Eq("Status", "a"), Gt("ExpiresAt", ""), Lt("ExpiresAt", "b")), statusExpiresAt))
+	assert.NilError(t, CheckIndexed(NewQuery(Eq("Status", "a"), Gt("ExpiresAt", "")).SortBy("Status", Asc).SortBy("ExpiresAt", Asc), statusExpiresAt))
+
+	// A field of the index is missing, or only in one branch of $or
+	assert.ErrorContains(t, CheckIndexed(NewQuery(In("Status", "a")), statusExpiresAt), "No index")
+	assert.ErrorContains(t, CheckIndexed(NewQuery(Eq("Status", "a"), Or(Gt("ExpiresAt", ""), Eq("Other", 1))), statusExpiresAt), "No index")
+	// The sort doesn't follow the index
+	assert.ErrorContains(t, CheckIndexed(NewQuery(Eq("Status", "a"), Gt("ExpiresAt", "")).SortBy("ExpiresAt", Asc), statusExpiresAt), "No index")
+	// The table's _id doesn't use an index
+	assert.ErrorContains(t, CheckIndexed(NewQuery(KeyPrefix("T", "key")), indexes), "No index")
+
+	query := NewQuery(Range("CreatedAt", "", "")).WithIndex("indexProposalUpdatedAtDoc", "indexProposalUpdatedAt")
+	assert.ErrorContains(t, CheckIndexed(query, indexes), "indexProposalUpdatedAtDoc/indexProposalUpdatedAt can't serve")
+
+	// use_index can name the design document only
+	query = NewQuery(In("Status", "a"), Gt("ExpiresAt", ""))
+	query.UseIndex = []string{"indexStatusExpiresAtDoc"}
+	assert.NilError(t, CheckIndexed(query, statusExpiresAt))
+	query.UseIndex = []string{"otherDoc"}
+	assert.ErrorContains(t, CheckIndexed(query, statusExpiresAt), "The index otherDoc can't serve")
+
+	// A query decoded from JSON
+	queryString, err := NewQuery(InTable("T"), Eq("Status", "a"), Gt("ExpiresAt", ""), Lt("ExpiresAt", "b")).String()
+	assert.NilError(t, err)
+	decoded := new(Query)
+	assert.NilError(t, json.Unmarshal([]byte(queryString), decoded))
+	assert.NilError(t, CheckIndexed(decoded, statusExpiresAt))
+}
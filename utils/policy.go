@@ -0,0 +1,426 @@
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Kinds of PolicyNode
+const (
+	PolicyAnd        = "AND"
+	PolicyOr         = "OR"
+	PolicyOutOf      = "OutOf"
+	PolicyGroup      = "Group"
+	PolicySuperAdmin = "SuperAdmin"
+)
+
+// Limits of a policy expression, so its evaluation stays cheap
+const (
+	maxPolicyLength = 1024
+	maxPolicyDepth  = 16
+)
+
+// PolicyNode is a node of a parsed approval policy.
+// An expression is written like a Fabric endorsement policy:
+//
+//	AND(policy, ...)            every policy is satisfied
+//	OR(policy, ...)             one policy is satisfied
+//	OutOf(n, policy, ...)       n of the policies are satisfied
+//	Group(n, 'GroupID')         n members of the approver group approved
+//	'SuperAdminID'              this SuperAdmin approved
+//
+// e.g. AND(Group(2, 'Finance'), Group(1, 'Security')) or OR(Group(3, '*'), 'CEO').
+type PolicyNode struct {
+	Kind     string        // AND/OR/OutOf/Group/SuperAdmin
+	N        int           // OutOf and Group: number needed
+	ID       string        // Group: GroupID, SuperAdmin: SuperAdminID
+	Children []*PolicyNode // AND/OR/OutOf
+}
+
+// ParsePolicy parses an approval policy expression
+func ParsePolicy(expression string) (*PolicyNode, error) {
+	if len(expression) > maxPolicyLength {
+		return nil, fmt.Errorf("The policy can't be longer than %d characters", maxPolicyLength)
+	}
+	tokens, err := tokenizePolicy(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &policyParser{tokens: tokens}
+	node, err := p.parse(0)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("Unexpected %s after the end of the policy", p.tokens[p.pos])
+	}
+	return node, nil
+}
+
+// Groups lists the IDs of the approver groups named by the policy
+func (node *PolicyNode) Groups() []string {
+	groups := []string{}
+	seen := map[string]bool{}
+	node.Walk(func(n *PolicyNode) {
+		if n.Kind == PolicyGroup && !seen[n.ID] {
+			seen[n.ID] = true
+			groups = append(groups, n.ID)
+		}
+	})
+	return groups
+}
+
+// SuperAdmins lists the IDs of the SuperAdmins named by the policy
+func (node *PolicyNode) SuperAdmins() []string {
+	superAdmins := []string{}
+	seen := map[string]bool{}
+	node.Walk(func(n *PolicyNode) {
+		if n.Kind == PolicySuperAdmin && !seen[n.ID] {
+			seen[n.ID] = true
+			superAdmins = append(superAdmins, n.ID)
+		}
+	})
+	return superAdmins
+}
+
+// Satisfied reports whether the SuperAdmins who approved satisfy the policy.
+// members holds the SuperAdminIDs of every group the policy names.
+func (node *PolicyNode) Satisfied(approved map[string]bool, members map[string][]string) bool {
+	switch node.Kind {
+	case PolicySuperAdmin:
+		return approved[node.ID]
+	case PolicyGroup:
+		count := 0
+		for _, member := range members[node.ID] {
+			if approved[member] {
+				count++
+			}
+		}
+		return count >= node.N
+	}
+
+	count := 0
+	for _, child := range node.Children {
+		if child.Satisfied(approved, members) {
+			count++
+		}
+	}
+	switch node.Kind {
+	case PolicyAnd:
+		return count == len(node.Children)
+	case PolicyOr:
+		return count >= 1
+	}
+	return count >= node.N
+}
+
+// Needed returns how many more SuperAdmins, among the pending ones, must approve to satisfy the policy,
+// or -1 if they can't. The count is exact unless a SuperAdmin is named more than once, e.g. in two groups,
+// then it is an upper bound.
+func (node *PolicyNode) Needed(approved map[string]bool, pending map[string]bool, members map[string][]string) int {
+	switch node.Kind {
+	case PolicySuperAdmin:
+		if approved[node.ID] {
+			return 0
+		}
+		if pending[node.ID] {
+			return 1
+		}
+		return -1
+	case PolicyGroup:
+		have, available := 0, 0
+		for _, member := range members[node.ID] {
+			if approved[member] {
+				have++
+			} else if pending[member] {
+				available++
+			}
+		}
+		if have >= node.N {
+			return 0
+		}
+		if have+available < node.N {
+			return -1
+		}
+		return node.N - have
+	}
+
+	needs := []int{}
+	for _, child := range node.Children {
+		need := child.Needed(approved, pending, members)
+		if need >= 0 {
+			needs = append(needs, need)
+		}
+	}
+	sort.Ints(needs)
+
+	n := node.N
+	switch node.Kind {
+	case PolicyAnd:
+		n = len(node.Children)
+	case PolicyOr:
+		n = 1
+	}
+	if len(needs) < n {
+		return -1
+	}
+	total := 0
+	for _, need := range needs[:n] {
+		total += need
+	}
+	return total
+}
+
+// MinApprovers returns a lower bound of the number of distinct SuperAdmins who must approve to satisfy the policy.
+// The bound is exact when the SuperAdmins the policies of an AND or OutOf can count are distinct, otherwise
+// they may be shared and only the largest of them counts.
+func (node *PolicyNode) MinApprovers(members map[string][]string) int {
+	switch node.Kind {
+	case PolicySuperAdmin:
+		return 1
+	case PolicyGroup:
+		return node.N
+	}
+
+	mins := []int{}
+	for _, child := range node.Children {
+		mins = append(mins, child.MinApprovers(members))
+	}
+	sort.Ints(mins)
+
+	n := node.N
+	switch node.Kind {
+	case PolicyAnd:
+		n = len(node.Children)
+	case PolicyOr:
+		n = 1
+	}
+	if !node.disjointChildren(members) {
+		return mins[n-1]
+	}
+	total := 0
+	for _, min := range mins[:n] {
+		total += min
+	}
+	return total
+}
+
+// disjointChildren func to check whether no SuperAdmin can count for two children of the node
+func (node *PolicyNode) disjointChildren(members map[string][]string) bool {
+	seen := map[string]bool{}
+	for _, child := range node.Children {
+		candidates := map[string]bool{}
+		child.Walk(func(n *PolicyNode) {
+			switch n.Kind {
+			case PolicySuperAdmin:
+				candidates[n.ID] = true
+			case PolicyGroup:
+				for _, member := range members[n.ID] {
+					candidates[member] = true
+				}
+			}
+		})
+		for candidate := range candidates {
+			if seen[candidate] {
+				return false
+			}
+			seen[candidate] = true
+		}
+	}
+	return true
+}
+
+// Walk calls fn on the node and all its descendants
+func (node *PolicyNode) Walk(fn func(*PolicyNode)) {
+	fn(node)
+	for _, child := range node.Children {
+		child.Walk(fn)
+	}
+}
+
+// tokenizePolicy func to split a policy expression into names, numbers, quoted IDs and punctuation.
+// A quoted ID keeps its quotes so it can't be confused with a name.
+func tokenizePolicy(expression string) ([]string, error) {
+	tokens := []string{}
+	for i := 0; i < len(expression); {
+		c := expression[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(' || c == ')' || c == ',':
+			tokens = append(tokens, string(c))
+			i++
+		case c == '\'':
+			end := strings.IndexByte(expression[i+1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("Unterminated quote in the policy")
+			}
+			tokens = append(tokens, expression[i:i+end+2])
+			i += end + 2
+		case isPolicyWordChar(c):
+			start := i
+			for i < len(expression) && isPolicyWordChar(expression[i]) {
+				i++
+			}
+			tokens = append(tokens, expression[start:i])
+		default:
+			return nil, fmt.Errorf("Unexpected character %q in the policy", c)
+		}
+	}
+	return tokens, nil
+}
+
+// isPolicyWordChar func to check whether c can be part of a name or a number
+func isPolicyWordChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// policyParser is a recursive descent parser over the tokens of a policy expression
+type policyParser struct {
+	tokens []string
+	pos    int
+}
+
+// next func to consume the next token, empty at the end of the expression
+func (p *policyParser) next() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	token := p.tokens[p.pos]
+	p.pos++
+	return token
+}
+
+// expect func to consume the next token, it must be token
+func (p *policyParser) expect(token string) error {
+	got := p.next()
+	if got != token {
+		if got == "" {
+			got = "the end of the policy"
+		}
+		return fmt.Errorf("Expected %s but found %s in the policy", token, got)
+	}
+	return nil
+}
+
+// parse func to parse a policy at the current position
+func (p *policyParser) parse(depth int) (*PolicyNode, error) {
+	if depth >= maxPolicyDepth {
+		return nil, fmt.Errorf("The policy can't be nested more than %d levels", maxPolicyDepth)
+	}
+
+	token := p.next()
+	if id, ok := unquote(token); ok {
+		if len(id) == 0 {
+			return nil, fmt.Errorf("A SuperAdminID can't be empty in the policy")
+		}
+		return &PolicyNode{Kind: PolicySuperAdmin, ID: id}, nil
+	}
+
+	switch token {
+	case PolicyAnd, PolicyOr:
+		err := p.expect("(")
+		if err != nil {
+			return nil, err
+		}
+		children, err := p.parseList(depth)
+		if err != nil {
+			return nil, err
+		}
+		return &PolicyNode{Kind: token, Children: children}, nil
+
+	case PolicyOutOf:
+		err := p.expect("(")
+		if err != nil {
+			return nil, err
+		}
+		n, err := p.parseNumber()
+		if err != nil {
+			return nil, err
+		}
+		err = p.expect(",")
+		if err != nil {
+			return nil, err
+		}
+		children, err := p.parseList(depth)
+		if err != nil {
+			return nil, err
+		}
+		if n > len(children) {
+			return nil, fmt.Errorf("OutOf(%d, ...) has only %d policies", n, len(children))
+		}
+		return &PolicyNode{Kind: PolicyOutOf, N: n, Children: children}, nil
+
+	case PolicyGroup:
+		err := p.expect("(")
+		if err != nil {
+			return nil, err
+		}
+		n, err := p.parseNumber()
+		if err != nil {
+			return nil, err
+		}
+		err = p.expect(",")
+		if err != nil {
+			return nil, err
+		}
+		id, ok := unquote(p.next())
+		if !ok || len(id) == 0 {
+			return nil, fmt.Errorf("Group(%d, ...) expects a quoted GroupID", n)
+		}
+		err = p.expect(")")
+		if err != nil {
+			return nil, err
+		}
+		return &PolicyNode{Kind: PolicyGroup, N: n, ID: id}, nil
+	}
+
+	if token == "" {
+		token = "the end of the policy"
+	}
+	return nil, fmt.Errorf("Unexpected %s in the policy", token)
+}
+
+// parseList func to parse the policies of AND, OR or OutOf up to the closing parenthesis
+func (p *policyParser) parseList(depth int) ([]*PolicyNode, error) {
+	children := []*PolicyNode{}
+	for {
+		child, err := p.parse(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, child)
+
+		token := p.next()
+		if token == ")" {
+			return children, nil
+		}
+		if token != "," {
+			if token == "" {
+				token = "the end of the policy"
+			}
+			return nil, fmt.Errorf("Expected , or ) but found %s in the policy", token)
+		}
+	}
+}
+
+// parseNumber func to parse the positive number of OutOf or Group
+func (p *policyParser) parseNumber() (int, error) {
+	token := p.next()
+	n, err := strconv.Atoi(token)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("Expected a positive number but found %s in the policy", token)
+	}
+	return n, nil
+}
+
+// unquote func to get the ID of a quoted token
+func unquote(token string) (string, bool) {
+	if len(token) < 2 || token[0] != '\'' || token[len(token)-1] != '\'' {
+		return "", false
+	}
+	return token[1 : len(token)-1], true
+}
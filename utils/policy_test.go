@@ -0,0 +1,110 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestParsePolicy(t *testing.T) {
+	policy, err := ParsePolicy("AND(Group(2, 'Finance'), OR('CEO', Group(1, 'Security')))")
+	assert.NilError(t, err)
+	assert.Equal(t, PolicyAnd, policy.Kind)
+	assert.Equal(t, 2, len(policy.Children))
+	assert.DeepEqual(t, []string{"Finance", "Security"}, policy.Groups())
+	assert.DeepEqual(t, []string{"CEO"}, policy.SuperAdmins())
+
+	for expression, message := range map[string]string{
+		"":                         "Unexpected the end",
+		"AND('a' 'b')":             "Expected , or )",
+		"OutOf(3, 'a', 'b')":       "has only 2 policies",
+		"OutOf(0, 'a')":            "positive number",
+		"Group(1, Finance)":        "quoted GroupID",
+		"Group(1, 'Finance'":       "Expected )",
+		"'a', 'b'":                 "after the end",
+		"'unterminated":            "Unterminated quote",
+		"NOT('a')":                 "Unexpected NOT",
+		"AND('a'; 'b')":            "Unexpected character",
+		"''":                       "can't be empty",
+		strings.Repeat("AND(", 20): "nested",
+	} {
+		_, err = ParsePolicy(expression)
+		assert.ErrorContains(t, err, message, expression)
+	}
+}
+
+func TestPolicySatisfied(t *testing.T) {
+	members := map[string][]string{
+		"Finance":  {"alice", "bob", "carol"},
+		"Security": {"dave"},
+		"*":        {"alice", "bob", "carol", "dave", "ceo"},
+	}
+
+	policy, err := ParsePolicy("AND(Group(2, 'Finance'), Group(1, 'Security'))")
+	assert.NilError(t, err)
+	assert.Assert(t, !policy.Satisfied(map[string]bool{"alice": true, "bob": true}, members))
+	assert.Assert(t, !policy.Satisfied(map[string]bool{"alice": true, "dave": true}, members))
+	assert.Assert(t, policy.Satisfied(map[string]bool{"alice": true, "bob": true, "dave": true}, members))
+
+	policy, err = ParsePolicy("OR(Group(3, '*'), 'ceo')")
+	assert.NilError(t, err)
+	assert.Assert(t, policy.Satisfied(map[string]bool{"ceo": true}, members))
+	assert.Assert(t, !policy.Satisfied(map[string]bool{"alice": true, "dave": true}, members))
+	assert.Assert(t, policy.Satisfied(map[string]bool{"alice": true, "bob": true, "dave": true}, members))
+
+	policy, err = ParsePolicy("OutOf(2, 'alice', 'bob', 'carol')")
+	assert.NilError(t, err)
+	assert.Assert(t, !policy.Satisfied(map[string]bool{"carol": true}, members))
+	assert.Assert(t, policy.Satisfied(map[string]bool{"alice": true, "carol": true}, members))
+}
+
+func TestPolicyNeeded(t *testing.T) {
+	members := map[string][]string{
+		"Finance":  {"alice", "bob", "carol"},
+		"Security": {"dave"},
+	}
+
+	policy, err := ParsePolicy("AND(Group(2, 'Finance'), Group(1, 'Security'))")
+	assert.NilError(t, err)
+	pending := map[string]bool{"bob": true, "carol": true, "dave": true}
+	assert.Equal(t, 2, policy.Needed(map[string]bool{"alice": true}, pending, members))
+	assert.Equal(t, 0, policy.Needed(map[string]bool{"alice": true, "bob": true, "dave": true}, map[string]bool{}, members))
+
+	// dave rejected the proposal
+	assert.Equal(t, -1, policy.Needed(map[string]bool{"alice": true}, map[string]bool{"bob": true, "carol": true}, members))
+
+	policy, err = ParsePolicy("OR(Group(3, 'Finance'), 'ceo')")
+	assert.NilError(t, err)
+	assert.Equal(t, 1, policy.Needed(map[string]bool{}, map[string]bool{"alice": true, "bob": true, "carol": true, "ceo": true}, members))
+	assert.Equal(t, 3, policy.Needed(map[string]bool{}, map[string]bool{"alice": true, "bob": true, "carol": true}, members))
+
+	policy, err = ParsePolicy("OutOf(2, 'alice', Group(1, 'Security'), 'ceo')")
+	assert.NilError(t, err)
+	assert.Equal(t, 1, policy.Needed(map[string]bool{"alice": true}, map[string]bool{"dave": true}, members))
+	assert.Equal(t, -1, policy.Needed(map[string]bool{}, map[string]bool{"dave": true}, members))
+}
+
+func TestPolicyMinApprovers(t *testing.T) {
+	members := map[string][]string{
+		"Finance":  {"alice", "bob", "carol"},
+		"Security": {"dave"},
+		"*":        {"alice", "bob", "carol", "dave", "ceo"},
+	}
+
+	for expression, min := range map[string]int{
+		"'ceo'":               1,
+		"Group(2, 'Finance')": 2,
+		"AND(Group(2, 'Finance'), Group(1, 'Security'))":  3,
+		"AND('alice', 'bob', 'dave')":                     3,
+		"OR(Group(3, '*'), 'ceo')":                        1,
+		"OutOf(2, 'alice', Group(1, 'Security'), 'ceo')":  2,
+		"OutOf(2, Group(2, 'Finance'), 'dave', 'ceo')":    2,
+		"AND(Group(2, 'Finance'), 'alice')":               2,
+		"AND(Group(2, 'Finance'), Group(3, '*'), 'dave')": 3,
+	} {
+		policy, err := ParsePolicy(expression)
+		assert.NilError(t, err)
+		assert.Equal(t, min, policy.MinApprovers(members), expression)
+	}
+}
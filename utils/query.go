@@ -0,0 +1,195 @@
+package utils
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/Akachain/akc-go-sdk/common"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+// Selector is a CouchDB Mango selector. Values are marshalled by encoding/json,
+// so a value can't change the structure of the query.
+type Selector map[string]interface{}
+
+// Query is a CouchDB Mango query
+type Query struct {
+	Selector Selector            `json:"selector"`
+	Sort     []map[string]string `json:"sort,omitempty"`
+	Fields   []string            `json:"fields,omitempty"`
+	Limit    int                 `json:"limit,omitempty"`
+	Bookmark string              `json:"bookmark,omitempty"`
+	UseIndex []string            `json:"use_index,omitempty"`
+}
+
+// Sort directions
+const (
+	Asc  = "asc"
+	Desc = "desc"
+)
+
+// NewQuery returns a query for the documents matching all the selectors
+func NewQuery(selectors ...Selector) *Query {
+	return &Query{Selector: And(selectors...)}
+}
+
+// SortBy adds a sort field, in direction Asc or Desc
+func (q *Query) SortBy(field string, direction string) *Query {
+	q.Sort = append(q.Sort, map[string]string{field: direction})
+	return q
+}
+
+// WithFields restricts the fields of the returned documents
+func (q *Query) WithFields(fields ...string) *Query {
+	q.Fields = append(q.Fields, fields...)
+	return q
+}
+
+// WithLimit sets the maximum number of returned documents
+func (q *Query) WithLimit(limit int) *Query {
+	q.Limit = limit
+	return q
+}
+
+// WithBookmark sets the bookmark of the page to return
+func (q *Query) WithBookmark(bookmark string) *Query {
+	q.Bookmark = bookmark
+	return q
+}
+
+// WithIndex makes CouchDB use the index name of the design document ddoc
+func (q *Query) WithIndex(ddoc string, name string) *Query {
+	q.UseIndex = []string{ddoc, name}
+	return q
+}
+
+// String returns the JSON of the query
+func (q *Query) String() (string, error) {
+	bytes, err := json.Marshal(q)
+	if err != nil {
+		return "", err
+	}
+	return string(bytes), nil
+}
+
+// InTable selects the rows of a table, whose keys are composite keys of the table
+func InTable(table string) Selector {
+	return KeyPrefix(table)
+}
+
+// KeyPrefix selects the rows of a table whose composite key starts with the keys
+func KeyPrefix(table string, keys ...string) Selector {
+	prefix := []string{"", table}
+	for _, key := range keys {
+		prefix = append(prefix, key)
+	}
+	// A composite key is \x00table\x00key1\x00key2\x00, anchor it so another table containing the name doesn't match
+	pattern := "^" + regexp.QuoteMeta(strings.Join(prefix, "\x00")+"\x00")
+	return Selector{"_id": map[string]interface{}{"$regex": pattern}}
+}
+
+// Eq selects the documents whose field equals value
+func Eq(field string, value interface{}) Selector {
+	return Selector{field: map[string]interface{}{"$eq": value}}
+}
+
+// In selects the documents whose field is one of values
+func In(field string, values ...interface{}) Selector {
+	if values == nil {
+		values = []interface{}{}
+	}
+	return Selector{field: map[string]interface{}{"$in": values}}
+}
+
+// Gt selects the documents whose field is greater than value
+func Gt(field string, value interface{}) Selector {
+	return Selector{field: map[string]interface{}{"$gt": value}}
+}
+
+// Gte selects the documents whose field is greater than or equal to value
+func Gte(field string, value interface{}) Selector {
+	return Selector{field: map[string]interface{}{"$gte": value}}
+}
+
+// Lt selects the documents whose field is less than value
+func Lt(field string, value interface{}) Selector {
+	return Selector{field: map[string]interface{}{"$lt": value}}
+}
+
+// Lte selects the documents whose field is less than or equal to value
+func Lte(field string, value interface{}) Selector {
+	return Selector{field: map[string]interface{}{"$lte": value}}
+}
+
+// Between selects the documents whose field is greater than from and less than to
+func Between(field string, from interface{}, to interface{}) Selector {
+	return Selector{field: map[string]interface{}{"$gt": from, "$lt": to}}
+}
+
+// Range selects the documents whose field is at least from and less than to. An empty bound is open,
+// but the field must still be set so an index on it can serve the query.
+func Range(field string, from string, to string) Selector {
+	condition := map[string]interface{}{}
+	if from != "" {
+		condition["$gte"] = from
+	} else {
+		condition["$gt"] = nil
+	}
+	if to != "" {
+		condition["$lt"] = to
+	}
+	return Selector{field: condition}
+}
+
+// Prefix selects the documents whose field starts with prefix, ignoring case
+func Prefix(field string, prefix string) Selector {
+	return Selector{field: map[string]interface{}{"$regex": "(?i)^" + regexp.QuoteMeta(prefix)}}
+}
+
+// And selects the documents matching all the selectors. Selectors on different fields are merged
+// into one selector, otherwise they are combined with $and.
+func And(selectors ...Selector) Selector {
+	merged := Selector{}
+	for _, selector := range selectors {
+		for field := range selector {
+			if _, ok := merged[field]; ok {
+				return Selector{"$and": selectors}
+			}
+		}
+		for field, condition := range selector {
+			merged[field] = condition
+		}
+	}
+	return merged
+}
+
+// Or selects the documents matching one of the selectors
+func Or(selectors ...Selector) Selector {
+	return Selector{"$or": selectors}
+}
+
+// GetQueryResult runs the query on the state database
+func GetQueryResult(stub shim.ChaincodeStubInterface, query *Query) (shim.StateQueryIteratorInterface, error) {
+	queryString, err := query.String()
+	if err != nil {
+		return nil, err
+	}
+	common.Logger.Info(queryString)
+	return stub.GetQueryResult(queryString)
+}
+
+// GetQueryResultWithPagination runs the query on the state database and returns a page of pageSize documents
+// after bookmark. The page replaces the query's limit and bookmark.
+func GetQueryResultWithPagination(stub shim.ChaincodeStubInterface, query *Query, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *pb.QueryResponseMetadata, error) {
+	pageQuery := *query
+	pageQuery.Limit = 0
+	pageQuery.Bookmark = ""
+	queryString, err := pageQuery.String()
+	if err != nil {
+		return nil, nil, err
+	}
+	common.Logger.Info(queryString)
+	return stub.GetQueryResultWithPagination(queryString, pageSize, bookmark)
+}
@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"encoding/json"
+	"regexp"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestQueryString(t *testing.T) {
+	query := NewQuery(InTable("HSTX_PROPOSAL"), In("Status", "Pending", "Approved"), Between("ExpiresAt", "", "2020-01-01T00:00:00Z")).
+		SortBy("CreatedAt", Desc).WithFields("ProposalID").WithLimit(10).WithBookmark("g1AAAA")
+	queryString, err := query.String()
+	assert.NilError(t, err)
+	assert.Equal(t, `{"selector":{"ExpiresAt":{"$gt":"","$lt":"2020-01-01T00:00:00Z"},"Status":{"$in":["Pending","Approved"]},`+
+		`"_id":{"$regex":"^\u0000HSTX_PROPOSAL\u0000"}},"sort":[{"CreatedAt":"desc"}],"fields":["ProposalID"],"limit":10,"bookmark":"g1AAAA"}`, queryString)
+
+	// Conditions on the same field can't be merged
+	queryString, err = NewQuery(Gt("ExpiresAt", "a"), Lt("ExpiresAt", "b")).String()
+	assert.NilError(t, err)
+	assert.Equal(t, `{"selector":{"$and":[{"ExpiresAt":{"$gt":"a"}},{"ExpiresAt":{"$lt":"b"}}]}}`, queryString)
+
+	queryString, err = NewQuery(Or(Eq("Status", "Pending"), Eq("Status", "Approved"))).String()
+	assert.NilError(t, err)
+	assert.Equal(t, `{"selector":{"$or":[{"Status":{"$eq":"Pending"}},{"Status":{"$eq":"Approved"}}]}}`, queryString)
+}
+
+func TestQueryInjection(t *testing.T) {
+	value := `x"}, "_id": {"$regex": ".*`
+	queryString, err := NewQuery(InTable("HSTX_APPROVAL"), Eq("ApproverID", value)).String()
+	assert.NilError(t, err)
+
+	var query struct {
+		Selector map[string]map[string]string `json:"selector"`
+	}
+	assert.NilError(t, json.Unmarshal([]byte(queryString), &query))
+	assert.Equal(t, 2, len(query.Selector))
+	assert.Equal(t, value, query.Selector["ApproverID"]["$eq"])
+	assert.Equal(t, "^\x00HSTX_APPROVAL\x00", query.Selector["_id"]["$regex"])
+}
+
+func TestKeyPrefix(t *testing.T) {
+	pattern := regexp.MustCompile(KeyPrefix("HSTX_APPROVAL", "p.1")["_id"].(map[string]interface{})["$regex"].(string))
+	assert.Assert(t, pattern.MatchString("\x00HSTX_APPROVAL\x00p.1\x00sa1\x00"))
+	// The key is matched whole, and so is the table
+	assert.Assert(t, !pattern.MatchString("\x00HSTX_APPROVAL\x00p.10\x00sa1\x00"))
+	assert.Assert(t, !pattern.MatchString("\x00HSTX_APPROVAL\x00px1\x00sa1\x00"))
+	assert.Assert(t, !pattern.MatchString("\x00OLD_HSTX_APPROVAL\x00p.1\x00sa1\x00"))
+
+	pattern = regexp.MustCompile(InTable("HSTX_PROPOSAL")["_id"].(map[string]interface{})["$regex"].(string))
+	assert.Assert(t, pattern.MatchString("\x00HSTX_PROPOSAL\x00p1\x00"))
+	assert.Assert(t, !pattern.MatchString("\x00HSTX_PROPOSAL_HISTORY\x00p1\x00"))
+}
+
+func TestRangeAndPrefix(t *testing.T) {
+	queryString, err := NewQuery(Range("CreatedAt", "", ""), Range("UpdatedAt", "2020-01-01T00:00:00Z", "2020-02-01T00:00:00Z"), Prefix("Message", "a.b*")).
+		WithIndex("indexProposalCreatedAtDoc", "indexProposalCreatedAt").String()
+	assert.NilError(t, err)
+	assert.Equal(t, `{"selector":{"CreatedAt":{"$gt":null},"Message":{"$regex":"(?i)^a\\.b\\*"},`+
+		`"UpdatedAt":{"$gte":"2020-01-01T00:00:00Z","$lt":"2020-02-01T00:00:00Z"}},"use_index":["indexProposalCreatedAtDoc","indexProposalCreatedAt"]}`, queryString)
+}
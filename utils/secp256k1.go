@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"fmt"
+
+	"github.com/Akachain/akc-go-sdk/common"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	secp256k1ecdsa "github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+)
+
+// The standard library only implements curves with a = -3, so secp256k1 (y² = x³ + 7),
+// used by many hardware wallets, is verified with the decred implementation, which btcec also uses.
+
+var (
+	oidPublicKeyECDSA      = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+	oidNamedCurveSecp256k1 = asn1.ObjectIdentifier{1, 3, 132, 0, 10}
+)
+
+// subjectPublicKeyInfo is the PKIX structure of a PEM public key
+type subjectPublicKeyInfo struct {
+	Algorithm pkix.AlgorithmIdentifier
+	PublicKey asn1.BitString
+}
+
+// parseSecp256k1PublicKey func to parse a DER PKIX public key on the secp256k1 curve.
+// It returns nil without error if the key is of another type.
+func parseSecp256k1PublicKey(der []byte) (*secp256k1.PublicKey, error) {
+	var spki subjectPublicKeyInfo
+	rest, err := asn1.Unmarshal(der, &spki)
+	if err != nil || len(rest) > 0 || !spki.Algorithm.Algorithm.Equal(oidPublicKeyECDSA) {
+		return nil, nil
+	}
+	var namedCurve asn1.ObjectIdentifier
+	_, err = asn1.Unmarshal(spki.Algorithm.Parameters.FullBytes, &namedCurve)
+	if err != nil || !namedCurve.Equal(oidNamedCurveSecp256k1) {
+		return nil, nil
+	}
+
+	// Only the uncompressed and compressed points of SEC 1, not the hybrid ones
+	point := spki.PublicKey.RightAlign()
+	uncompressed := len(point) == secp256k1.PubKeyBytesLenUncompressed && point[0] == secp256k1.PubKeyFormatUncompressed
+	compressed := len(point) == secp256k1.PubKeyBytesLenCompressed &&
+		(point[0] == secp256k1.PubKeyFormatCompressedEven || point[0] == secp256k1.PubKeyFormatCompressedOdd)
+	if !uncompressed && !compressed {
+		return nil, fmt.Errorf("Invalid secp256k1 public key %s", common.GetLine())
+	}
+
+	// The point is checked to be on the curve
+	pk, err := secp256k1.ParsePubKey(point)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid secp256k1 public key. Cause: %s %s", err.Error(), common.GetLine())
+	}
+	return pk, nil
+}
+
+func verifySecp256k1(publicKey crypto.PublicKey, hash crypto.Hash, data []byte, signature []byte) bool {
+	// R and S must be strict DER integers between 1 and the order minus 1
+	sig, err := secp256k1ecdsa.ParseDERSignature(signature)
+	if err != nil {
+		return false
+	}
+
+	// e is the leftmost bits of the digest, as many as the order
+	hashed := digest(hash, data)
+	if len(hashed) > 32 {
+		hashed = hashed[:32]
+	}
+	return sig.Verify(hashed, publicKey.(*secp256k1.PublicKey))
+}
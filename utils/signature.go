@@ -0,0 +1,219 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+
+	"github.com/Akachain/akc-go-sdk/common"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/hyperledger/fabric/bccsp/utils"
+)
+
+// Signature schemes a SuperAdmin's public key can be enrolled with
+const (
+	SchemeECDSAP256      = "ECDSA_P256"
+	SchemeECDSAP384      = "ECDSA_P384"
+	SchemeECDSASecp256k1 = "ECDSA_SECP256K1"
+	SchemeEd25519        = "ED25519"
+	SchemeRSAPKCS1v15    = "RSA_PKCS1V15"
+	SchemeRSAPSS         = "RSA_PSS"
+)
+
+// Hash algorithms used to digest the signed data, Ed25519 doesn't use any
+const (
+	HashSHA256 = "SHA256"
+	HashSHA384 = "SHA384"
+	HashSHA512 = "SHA512"
+)
+
+var hashes = map[string]crypto.Hash{
+	HashSHA256: crypto.SHA256,
+	HashSHA384: crypto.SHA384,
+	HashSHA512: crypto.SHA512,
+}
+
+// verifier checks a signature over data, hashed with hash unless the scheme hashes the data itself
+type verifier func(publicKey crypto.PublicKey, hash crypto.Hash, data []byte, signature []byte) bool
+
+// verifiers is the registry of the supported signature schemes
+var verifiers = map[string]verifier{
+	SchemeECDSAP256:      verifyECDSA,
+	SchemeECDSAP384:      verifyECDSA,
+	SchemeECDSASecp256k1: verifySecp256k1,
+	SchemeEd25519:        verifyEd25519,
+	SchemeRSAPKCS1v15:    verifyRSAPKCS1v15,
+	SchemeRSAPSS:         verifyRSAPSS,
+}
+
+// ParsePublicKey func to decode a PEM public key and detect its signature scheme.
+// RSA keys are detected as RSA_PKCS1V15, the PSS padding must be chosen explicitly.
+func ParsePublicKey(publicKey string) (crypto.PublicKey, string, error) {
+	pkBlock, _ := pem.Decode([]byte(publicKey))
+	if pkBlock == nil {
+		return nil, "", fmt.Errorf("Can't decode public key %s", common.GetLine())
+	}
+
+	// The standard library doesn't know secp256k1
+	pk, err := parseSecp256k1PublicKey(pkBlock.Bytes)
+	if err != nil {
+		return nil, "", err
+	}
+	if pk != nil {
+		return pk, SchemeECDSASecp256k1, nil
+	}
+
+	rawPk, err := x509.ParsePKIXPublicKey(pkBlock.Bytes)
+	if err != nil {
+		return nil, "", fmt.Errorf("Can't parse public key. Cause: %s %s", err.Error(), common.GetLine())
+	}
+
+	switch key := rawPk.(type) {
+	case *ecdsa.PublicKey:
+		switch key.Curve {
+		case elliptic.P256():
+			return key, SchemeECDSAP256, nil
+		case elliptic.P384():
+			return key, SchemeECDSAP384, nil
+		}
+		return nil, "", fmt.Errorf("Unsupported elliptic curve %s %s", key.Curve.Params().Name, common.GetLine())
+	case ed25519.PublicKey:
+		return key, SchemeEd25519, nil
+	case *rsa.PublicKey:
+		return key, SchemeRSAPKCS1v15, nil
+	}
+	return nil, "", fmt.Errorf("Unsupported public key type %T %s", rawPk, common.GetLine())
+}
+
+// ResolveSignatureScheme func to check the signature scheme and hash algorithm chosen for a public key.
+// Empty values are replaced by the defaults: the scheme detected from the key and SHA256.
+func ResolveSignatureScheme(publicKey string, scheme string, hashAlgorithm string) (string, string, error) {
+	_, keyScheme, err := ParsePublicKey(publicKey)
+	if err != nil {
+		return "", "", err
+	}
+
+	if len(scheme) == 0 {
+		scheme = keyScheme
+	}
+	compatible := scheme == keyScheme || (keyScheme == SchemeRSAPKCS1v15 && scheme == SchemeRSAPSS)
+	if !compatible {
+		return "", "", fmt.Errorf("Signature scheme %s can't be used with a %s key %s", scheme, keyScheme, common.GetLine())
+	}
+
+	if scheme == SchemeEd25519 {
+		if len(hashAlgorithm) > 0 {
+			return "", "", fmt.Errorf("Ed25519 signs the data itself, the hash algorithm must be empty %s", common.GetLine())
+		}
+		return scheme, "", nil
+	}
+	if len(hashAlgorithm) == 0 {
+		hashAlgorithm = HashSHA256
+	}
+	if _, ok := hashes[hashAlgorithm]; !ok {
+		return "", "", fmt.Errorf("Unsupported hash algorithm %s %s", hashAlgorithm, common.GetLine())
+	}
+	return scheme, hashAlgorithm, nil
+}
+
+// PublicKeyFingerprint func to get the hex SHA-256 of a PEM public key's value.
+// It is the same for every encoding of the key, e.g. a compressed and an uncompressed point.
+func PublicKeyFingerprint(publicKey string) (string, error) {
+	pk, scheme, err := ParsePublicKey(publicKey)
+	if err != nil {
+		return "", err
+	}
+
+	var value string
+	switch key := pk.(type) {
+	case *secp256k1.PublicKey:
+		value = fmt.Sprintf("%x:%x", key.X(), key.Y())
+	case *ecdsa.PublicKey:
+		value = fmt.Sprintf("%x:%x", key.X, key.Y)
+	case ed25519.PublicKey:
+		value = hex.EncodeToString(key)
+	case *rsa.PublicKey:
+		value = fmt.Sprintf("%x:%x", key.N, key.E)
+	}
+	fingerprint := sha256.Sum256([]byte(scheme + ":" + value))
+	return hex.EncodeToString(fingerprint[:]), nil
+}
+
+// VerifySignature func to verify a base64 signature over data with a PEM public key
+func VerifySignature(publicKey string, scheme string, hashAlgorithm string, data []byte, signature string) error {
+	if len(publicKey) == 0 {
+		return fmt.Errorf("publicKey is empty %s", common.GetLine())
+	}
+	if len(signature) == 0 {
+		return fmt.Errorf("signature is empty %s", common.GetLine())
+	}
+
+	scheme, hashAlgorithm, err := ResolveSignatureScheme(publicKey, scheme, hashAlgorithm)
+	if err != nil {
+		return err
+	}
+	pk, _, err := ParsePublicKey(publicKey)
+	if err != nil {
+		return err
+	}
+
+	// SIGNATURE
+	signatureByte, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("Can't decode signature. Cause: %s %s", err.Error(), common.GetLine())
+	}
+
+	// VERIFY
+	if verifiers[scheme](pk, hashes[hashAlgorithm], data, signatureByte) {
+		return nil
+	}
+	return fmt.Errorf("Verify failed %s", common.GetLine())
+}
+
+// Digest func to hash data with a hash algorithm resolved by ResolveSignatureScheme.
+// It returns nil for the empty algorithm of Ed25519, which signs the data itself.
+func Digest(hashAlgorithm string, data []byte) ([]byte, error) {
+	if len(hashAlgorithm) == 0 {
+		return nil, nil
+	}
+	hash, ok := hashes[hashAlgorithm]
+	if !ok {
+		return nil, fmt.Errorf("Unsupported hash algorithm %s %s", hashAlgorithm, common.GetLine())
+	}
+	return digest(hash, data), nil
+}
+
+// digest func to hash data with hash
+func digest(hash crypto.Hash, data []byte) []byte {
+	h := hash.New()
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+func verifyECDSA(publicKey crypto.PublicKey, hash crypto.Hash, data []byte, signature []byte) bool {
+	R, S, err := utils.UnmarshalECDSASignature(signature)
+	if err != nil {
+		return false
+	}
+	return ecdsa.Verify(publicKey.(*ecdsa.PublicKey), digest(hash, data), R, S)
+}
+
+func verifyEd25519(publicKey crypto.PublicKey, hash crypto.Hash, data []byte, signature []byte) bool {
+	return ed25519.Verify(publicKey.(ed25519.PublicKey), data, signature)
+}
+
+func verifyRSAPKCS1v15(publicKey crypto.PublicKey, hash crypto.Hash, data []byte, signature []byte) bool {
+	return rsa.VerifyPKCS1v15(publicKey.(*rsa.PublicKey), hash, digest(hash, data), signature) == nil
+}
+
+func verifyRSAPSS(publicKey crypto.PublicKey, hash crypto.Hash, data []byte, signature []byte) bool {
+	return rsa.VerifyPSS(publicKey.(*rsa.PublicKey), hash, digest(hash, data), signature, nil) == nil
+}
@@ -0,0 +1,232 @@
+package utils
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	secp256k1ecdsa "github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"github.com/hyperledger/fabric/bccsp/utils"
+	"gotest.tools/assert"
+)
+
+// The keys and signatures below were generated with openssl over signedMessage
+const signedMessage = "hstx approval"
+
+const secp256k1PublicKeyPEM = `-----BEGIN PUBLIC KEY-----
+MFYwEAYHKoZIzj0CAQYFK4EEAAoDQgAEqi87e+0k+msqqtPOR+JVtw0zhAqaQ9h4
+0C4yg5tGtM6/9dP5XXSaeyt2UaEe46Qbwa8kxm0hJdwWUICfTyazFw==
+-----END PUBLIC KEY-----`
+
+const secp256k1CompressedPublicKeyPEM = `-----BEGIN PUBLIC KEY-----
+MDYwEAYHKoZIzj0CAQYFK4EEAAoDIgADqi87e+0k+msqqtPOR+JVtw0zhAqaQ9h4
+0C4yg5tGtM4=
+-----END PUBLIC KEY-----`
+
+const secp256k1Signature = "MEQCIH5z0uDwrxL0E7NVKIltE1GMH1HmxJ9B0Jpvu8UVhLc9AiAwFjqLC49H1h9f85EQcAry8gf9oKh9V8gkkTYk9iLe4g=="
+
+const ed25519PublicKeyPEM = `-----BEGIN PUBLIC KEY-----
+MCowBQYDK2VwAyEAuo9aFF39a+mLmFk4EdyYfd0oscVmz1Ewtlmyp5aiz/I=
+-----END PUBLIC KEY-----`
+
+const ed25519Signature = "zkgG6fT88tLcp+zht1KMenw9IulJwW2xrdiNvBGPPBy3Y4DXbgNxdDuWf8KheoDEO1g/EeZYRtwNS7MvIdDPBA=="
+
+const rsaPublicKeyPEM = `-----BEGIN PUBLIC KEY-----
+MIIBIjANBgkqhkiG9w0BAQEFAAOCAQ8AMIIBCgKCAQEAsRVOqJtZyaPjTP3Qo56t
+OZ/UeioIcpMdTkcJutJf1yXAcT6cx4sORryQyQQkOBEFPY9mxviPAgRrw6FgNun7
+CMBmN6EPLQ+8grPKDp/006G4agFkJRnpnCd0CbFEd10jU3y0GVfQgDo0JWL9HcVY
+rWALtdigmvn5RZd8Vg4YbBYGvOKdKc18B8qQbm6iE35kyWxJHWNK1HnS5A3IKWEL
+os/8nBotierLRxFVGx4SCFatT5T3tIdFHf2PqlCAzb+J7ba/IXl3Vg+QfVHGUpso
+ZMs2Wg01twhQ+I6/nXb1m+UIZUArJ7lOrHuGytYC7yK4aEXANFnp9dDE3gIukuXJ
+hwIDAQAB
+-----END PUBLIC KEY-----`
+
+// rsaPKCS1v15Signature uses SHA256, rsaPSSSignature uses SHA384
+const rsaPKCS1v15Signature = "b5oElJb5vj3b9rrGwhTqZqx/G732cj/GFsMKLotyM2RKZ3DMJavoncnhovUf1Fy13+hZob4WbH4CPRRZI9yH+3ZHtFT8WQtyvS/x7MHVuCvrVJ3WjF6+QLf0HLI/SQXYP+cfsGFtDwehmDJHsAgGPt2+LQcBAdh+f7dQU/hgoQgwqKmDvKcKE8CnHD0U+l4wAEb8U54zgZhnoAfQtzQHsEzxtmp88jNDUQTF233ux51qRxEPeLJRv5rdEX9pSJB/M0LeYTDXDm/SOdw4du76KfTYsPEGl9/402aaJzP3hSXTkQldp8fCYeY5UzeZP9r/PsPhUNgMBw56naC1Dnn4wQ=="
+const rsaPSSSignature = "iVKstQaEZ0rpUX1Cib/jB304kHx/z7exUfvVGnkG0QJrulc0Hu54IFkbxLxqkTt0/GoF/JZ4jxhp+xrc7hchp/JicB2mia51RXyhuFljfd8iUmyEKis5FwillWp/d55qKUA18MKyH1qAQANE/7iQnX2XqIUHKthT+BqQe6w7BnJfkSPSR/bOPfs3A2cy5A+GjIx9KELDB0DnWzCzifpgBjzYOeA0QsFvhcmTsLOeboE1Jo2BGj/ijtdBYfJzz4XdVYlwOFuDGbu1mgklYv1YqfJe97VhUF2aTjpBITePB99JNNp059a8wG/WjPJrhrG1gm0Z8qX4wy2oI6mK12nVaQ=="
+
+func TestVerifySignature(t *testing.T) {
+	data := []byte(signedMessage)
+
+	// ECDSA P-256, the default scheme
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	der, _ := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	p256PublicKeyPEM := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	hashed := sha256.Sum256(data)
+	r, s, _ := ecdsa.Sign(rand.Reader, key, hashed[:])
+	sig, _ := utils.MarshalECDSASignature(r, s)
+	p256Signature := base64.StdEncoding.EncodeToString(sig)
+
+	tests := []struct {
+		name      string
+		publicKey string
+		scheme    string
+		hash      string
+		signature string
+	}{
+		{"ECDSA P-256", p256PublicKeyPEM, "", "", p256Signature},
+		{"secp256k1", secp256k1PublicKeyPEM, SchemeECDSASecp256k1, HashSHA256, secp256k1Signature},
+		{"secp256k1 compressed", secp256k1CompressedPublicKeyPEM, "", "", secp256k1Signature},
+		{"Ed25519", ed25519PublicKeyPEM, "", "", ed25519Signature},
+		{"RSA PKCS#1 v1.5", rsaPublicKeyPEM, "", "", rsaPKCS1v15Signature},
+		{"RSA-PSS", rsaPublicKeyPEM, SchemeRSAPSS, HashSHA384, rsaPSSSignature},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.NilError(t, VerifySignature(test.publicKey, test.scheme, test.hash, data, test.signature))
+			assert.Assert(t, VerifySignature(test.publicKey, test.scheme, test.hash, []byte("tampered"), test.signature) != nil)
+		})
+	}
+
+	// Wrong hash or padding
+	assert.Assert(t, VerifySignature(rsaPublicKeyPEM, SchemeRSAPSS, HashSHA256, data, rsaPSSSignature) != nil)
+	assert.Assert(t, VerifySignature(rsaPublicKeyPEM, SchemeRSAPKCS1v15, HashSHA384, data, rsaPSSSignature) != nil)
+	// A secp256k1 signature checked against another key
+	assert.Assert(t, VerifySignature(p256PublicKeyPEM, "", "", data, secp256k1Signature) != nil)
+}
+
+// secp256k1PEM func to encode a secp256k1 point, valid or not, as a PEM public key
+func secp256k1PEM(point []byte) string {
+	curve, _ := asn1.Marshal(oidNamedCurveSecp256k1)
+	der, _ := asn1.Marshal(subjectPublicKeyInfo{
+		Algorithm: pkix.AlgorithmIdentifier{Algorithm: oidPublicKeyECDSA, Parameters: asn1.RawValue{FullBytes: curve}},
+		PublicKey: asn1.BitString{Bytes: point, BitLength: 8 * len(point)},
+	})
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func TestSecp256k1EdgeCases(t *testing.T) {
+	data := []byte(signedMessage)
+	n := secp256k1.S256().Params().N
+	one := big.NewInt(1)
+	nMinus1 := new(big.Int).Sub(n, one)
+	signature, _ := base64.StdEncoding.DecodeString(secp256k1Signature)
+	r, s, err := utils.UnmarshalECDSASignature(signature)
+	assert.NilError(t, err)
+
+	// encode func to build a signature from r and s
+	encode := func(r, s *big.Int) string {
+		sig, _ := asn1.Marshal(struct{ R, S *big.Int }{r, s})
+		return base64.StdEncoding.EncodeToString(sig)
+	}
+
+	// A signature with a high S is as valid as the low S one, as in ECDSA
+	assert.NilError(t, VerifySignature(secp256k1PublicKeyPEM, "", "", data, encode(r, new(big.Int).Sub(n, s))))
+
+	// R and S out of 1 to the order minus 1 are rejected, the bounds and other values don't verify
+	for name, rs := range map[string][2]*big.Int{
+		"r = 0":     {big.NewInt(0), s},
+		"s = 0":     {r, big.NewInt(0)},
+		"r = n":     {n, s},
+		"s = n":     {r, n},
+		"r = n + r": {new(big.Int).Add(n, r), s},
+		"r = -r":    {new(big.Int).Neg(r), s},
+		"r = n - 1": {nMinus1, s},
+		"s = n - 1": {r, nMinus1},
+		"r = s = 1": {one, one},
+	} {
+		assert.Assert(t, VerifySignature(secp256k1PublicKeyPEM, "", "", data, encode(rs[0], rs[1])) != nil, name)
+	}
+
+	// Only strict DER is accepted
+	padded := append([]byte{0x30, byte(len(signature) + 1), 0x02, byte(signature[3] + 1), 0x00}, signature[4:]...)
+	trailing := append(append([]byte{}, signature...), 0x00)
+	for name, sig := range map[string][]byte{"padded": padded, "trailing": trailing, "empty": {}} {
+		assert.Assert(t, VerifySignature(secp256k1PublicKeyPEM, "", "", data, base64.StdEncoding.EncodeToString(sig)) != nil, name)
+	}
+
+	// The point must be a compressed or uncompressed point of the curve, not the point at infinity
+	pk, _, err := ParsePublicKey(secp256k1PublicKeyPEM)
+	assert.NilError(t, err)
+	key := pk.(*secp256k1.PublicKey).SerializeUncompressed()
+	offCurve := append([]byte{}, key...)
+	offCurve[64] ^= 1
+	hybrid := append([]byte{0x06 | key[64]&1}, key[1:]...)
+	tooBigX := append([]byte{0x02}, n.Bytes()...)
+	for i := 1; i < 33; i++ {
+		tooBigX[i] = 0xff
+	}
+	for name, point := range map[string][]byte{"off curve": offCurve, "hybrid": hybrid, "x >= p": tooBigX, "infinity": {0x00}} {
+		_, _, err = ParsePublicKey(secp256k1PEM(point))
+		assert.Assert(t, err != nil, name)
+	}
+
+	// A key and signature made by the library's own signer
+	privateKey, err := secp256k1.GeneratePrivateKey()
+	assert.NilError(t, err)
+	hashed := sha256.Sum256(data)
+	generated := base64.StdEncoding.EncodeToString(secp256k1ecdsa.Sign(privateKey, hashed[:]).Serialize())
+	generatedPEM := secp256k1PEM(privateKey.PubKey().SerializeCompressed())
+	assert.NilError(t, VerifySignature(generatedPEM, "", "", data, generated))
+	assert.Assert(t, VerifySignature(secp256k1PublicKeyPEM, "", "", data, generated) != nil)
+}
+
+func TestResolveSignatureScheme(t *testing.T) {
+	scheme, hash, err := ResolveSignatureScheme(secp256k1CompressedPublicKeyPEM, "", "")
+	assert.NilError(t, err)
+	assert.Equal(t, SchemeECDSASecp256k1, scheme)
+	assert.Equal(t, HashSHA256, hash)
+
+	scheme, hash, err = ResolveSignatureScheme(ed25519PublicKeyPEM, "", "")
+	assert.NilError(t, err)
+	assert.Equal(t, SchemeEd25519, scheme)
+	assert.Equal(t, "", hash)
+
+	scheme, _, err = ResolveSignatureScheme(rsaPublicKeyPEM, SchemeRSAPSS, HashSHA512)
+	assert.NilError(t, err)
+	assert.Equal(t, SchemeRSAPSS, scheme)
+
+	// The scheme must match the key
+	_, _, err = ResolveSignatureScheme(ed25519PublicKeyPEM, SchemeECDSAP256, "")
+	assert.ErrorContains(t, err, "can't be used")
+	_, _, err = ResolveSignatureScheme(ed25519PublicKeyPEM, "", HashSHA256)
+	assert.ErrorContains(t, err, "must be empty")
+	_, _, err = ResolveSignatureScheme(rsaPublicKeyPEM, "", "MD5")
+	assert.ErrorContains(t, err, "Unsupported hash")
+	_, _, err = ResolveSignatureScheme("not a key", "", "")
+	assert.ErrorContains(t, err, "Can't decode")
+}
+
+func TestDigest(t *testing.T) {
+	data := []byte(signedMessage)
+	hashed := sha256.Sum256(data)
+	digest, err := Digest(HashSHA256, data)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, hashed[:], digest)
+
+	digest, err = Digest(HashSHA512, data)
+	assert.NilError(t, err)
+	assert.Equal(t, 64, len(digest))
+
+	// Ed25519 signs the data itself
+	digest, err = Digest("", data)
+	assert.NilError(t, err)
+	assert.Assert(t, digest == nil)
+
+	_, err = Digest("MD5", data)
+	assert.ErrorContains(t, err, "Unsupported hash")
+}
+
+func TestPublicKeyFingerprint(t *testing.T) {
+	uncompressed, err := PublicKeyFingerprint(secp256k1PublicKeyPEM)
+	assert.NilError(t, err)
+	compressed, err := PublicKeyFingerprint(secp256k1CompressedPublicKeyPEM)
+	assert.NilError(t, err)
+	assert.Equal(t, uncompressed, compressed)
+
+	other, err := PublicKeyFingerprint(ed25519PublicKeyPEM)
+	assert.NilError(t, err)
+	assert.Assert(t, other != compressed)
+
+	_, err = PublicKeyFingerprint("not a key")
+	assert.ErrorContains(t, err, "Can't decode")
+}
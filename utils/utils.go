@@ -1,16 +1,12 @@
 package utils
 
 import (
-	"crypto/ecdsa"
 	"crypto/sha256"
-	"crypto/x509"
-	"encoding/base64"
-	"encoding/pem"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/Akachain/akc-go-sdk/common"
-	"github.com/hyperledger/fabric/bccsp/utils"
 	"github.com/hyperledger/fabric/core/chaincode/lib/cid"
 	"github.com/hyperledger/fabric/core/chaincode/shim"
 )
@@ -23,6 +19,15 @@ func GenerateDocumentID(stub shim.ChaincodeStubInterface) string {
 	return fmt.Sprintf("%x", sum[0:19])
 }
 
+// GetTxTime func to get the transaction's timestamp in UTC
+func GetTxTime(stub shim.ChaincodeStubInterface) (time.Time, error) {
+	timestamp, err := stub.GetTxTimestamp()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Can't get transaction timestamp. Cause: %s %s", err.Error(), common.GetLine())
+	}
+	return time.Unix(timestamp.Seconds, int64(timestamp.Nanos)).UTC(), nil
+}
+
 // GetCertID func to get Certtificate ID of current user
 func GetCertID(stub shim.ChaincodeStubInterface) (*string, error) {
 	id, err := cid.GetID(stub)
@@ -58,107 +63,54 @@ func GetAttributeValue(stub shim.ChaincodeStubInterface, attrName string) (*stri
 	return &val, nil
 }
 
+// Values of the 'hstx.role' attribute
+const (
+	// RoleSuperAdmin is owned by the members of the Quorum, who approve or reject proposals and manage the Admins
+	RoleSuperAdmin = "SuperAdmin"
+	// RoleAdmin is owned by the Admins, who create proposals
+	RoleAdmin = "Admin"
+	// RoleAuditor can only call the read-only functions
+	RoleAuditor = "Auditor"
+)
+
 // IsSuperAdmin func to check role Super Admin of caller. Return nil if true
 func IsSuperAdmin(stub shim.ChaincodeStubInterface) error {
-	role, err := GetAttributeValue(stub, "hstx.role")
-	if err != nil {
-		return err
-	}
-
-	if strings.Compare("SuperAdmin", *role) != 0 {
-		return fmt.Errorf("This certificate doesn't contain role SuperAdmin. Cause: %s", common.GetLine())
-	}
-	return nil
+	return HasRole(stub, RoleSuperAdmin)
 }
 
-// GetByOneColumn func to get information
-func GetByOneColumn(stub shim.ChaincodeStubInterface, table string, column string, value interface{}) (resultsIterator shim.StateQueryIteratorInterface, err error) {
-	queryString := fmt.Sprintf("{\"selector\": {\"_id\": {\"$regex\": \"%s\"},\"%s\": %v}}", table, column, value)
-	common.Logger.Info(queryString)
-	resultsIterator, err = stub.GetQueryResult(queryString)
+// HasRole func to check the caller's 'hstx.role' attribute is one of roles. Return nil if true
+func HasRole(stub shim.ChaincodeStubInterface, roles ...string) error {
+	role, err := GetAttributeValue(stub, "hstx.role")
 	if err != nil {
-		return nil, err
+		return err
 	}
-	return resultsIterator, nil
-}
 
-// GetByTwoColumns func to get information
-func GetByTwoColumns(stub shim.ChaincodeStubInterface, table string, column1 string, value1 interface{}, column2 string, value2 interface{}) (resultsIterator shim.StateQueryIteratorInterface, err error) {
-	queryString := fmt.Sprintf("{\"selector\": {\"_id\": {\"$regex\": \"%s\"},\"%s\": %v, \"%s\": %v}}", table, column1, value1, column2, value2)
-	common.Logger.Info(queryString)
-	resultsIterator, err = stub.GetQueryResult(queryString)
-	if err != nil {
-		return nil, err
+	for _, r := range roles {
+		if strings.Compare(r, *role) == 0 {
+			return nil
+		}
 	}
-	return resultsIterator, nil
+	return fmt.Errorf("This certificate doesn't contain role %s. Cause: %s", strings.Join(roles, " or "), common.GetLine())
 }
 
-// GetContainKey func to get information
-func GetContainKey(stub shim.ChaincodeStubInterface, table string, key string) (resultsIterator shim.StateQueryIteratorInterface, err error) {
-	queryString := fmt.Sprintf(`
-		{ "selector": 
-			{
-				"_id": 
-					{"$gt": "\u0000%s\u0000%s",
-					"$lt": "\u0000%s\u0000%s\uFFFF"}
-			}
-		}`, table, key, table, key)
-	common.Logger.Info(queryString)
-	resultsIterator, err = stub.GetQueryResult(queryString)
-	if err != nil {
-		return nil, err
-	}
-	return resultsIterator, nil
-}
-
-// verifySignature func base on approver's public key, signature and message that was singed
-func verifySignature(stub shim.ChaincodeStubInterface, publicKey string, signature string, message string) error {
-	if len(publicKey) == 0 {
-		return fmt.Errorf("publicKey is empty %s", common.GetLine())
-	}
-	if len(signature) == 0 {
-		return fmt.Errorf("signature is empty %s", common.GetLine())
-	}
-
-	// Start verify
-	pkBytes := []byte(publicKey)
-	pkBlock, _ := pem.Decode(pkBytes)
-	if pkBlock == nil {
-		return fmt.Errorf("Can't decode public key %s", common.GetLine())
-	}
-
-	rawPk, err := x509.ParsePKIXPublicKey(pkBlock.Bytes)
+// IsInvoker func to check the caller's certificate is the identity mspID/certID. Return nil if true
+func IsInvoker(stub shim.ChaincodeStubInterface, mspID string, certID string) error {
+	callerMSPID, err := GetMSPID(stub)
 	if err != nil {
 		return err
 	}
-
-	pk := rawPk.(*ecdsa.PublicKey)
-
-	// SIGNATURE
-	signaturebyte, err := base64.StdEncoding.DecodeString(signature)
+	callerCertID, err := GetCertID(stub)
 	if err != nil {
 		return err
 	}
-
-	R, S, err := utils.UnmarshalECDSASignature(signaturebyte)
-	if err != nil {
-		return err
+	if len(certID) == 0 || *callerMSPID != mspID || *callerCertID != certID {
+		return fmt.Errorf("The caller isn't the expected identity %s", common.GetLine())
 	}
+	return nil
+}
 
-	// DATA
-	dataByte, err := base64.StdEncoding.DecodeString(message)
-	if err != nil {
-		return err
-	}
-
-	hash := sha256.Sum256(dataByte)
-	var hashData = hash[:]
-
-	// VERIFY
-	checksign := ecdsa.Verify(pk, hashData, R, S)
-
-	if checksign {
-		return nil
-	}
-	return fmt.Errorf("Verify failed %s", common.GetLine())
-}
\ No newline at end of file
+// GetContainKey func to get the rows of the table whose composite key starts with key.
+// It is a range scan of the keys, so it needs no index.
+func GetContainKey(stub shim.ChaincodeStubInterface, table string, key string) (resultsIterator shim.StateQueryIteratorInterface, err error) {
+	return stub.GetStateByPartialCompositeKey(table, []string{key})
+}
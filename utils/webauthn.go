@@ -0,0 +1,101 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Akachain/akc-go-sdk/common"
+)
+
+// Flags of the WebAuthn authenticator data
+const (
+	// FlagUserPresent is set when the user touched the authenticator
+	FlagUserPresent byte = 0x01
+	// FlagUserVerified is set when the authenticator verified the user, by PIN or biometrics
+	FlagUserVerified byte = 0x04
+)
+
+// authenticatorDataMinLength is the length of rpIdHash (32 bytes), flags (1 byte) and signCount (4 bytes)
+const authenticatorDataMinLength = 37
+
+// clientData contains the fields of the WebAuthn CollectedClientData checked by the chaincode
+type clientData struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	Origin    string `json:"origin"`
+}
+
+// CheckWebAuthnAssertion func to check a WebAuthn assertion issued for rpID over challenge.
+// It returns the data signed by the authenticator, authenticatorData || sha256(clientDataJSON),
+// and the authenticator's signature counter. The signature itself must be verified by the caller.
+func CheckWebAuthnAssertion(authenticatorData []byte, clientDataJSON []byte, rpID string, challenge []byte, requireUserVerification bool) (signedData []byte, signCount uint32, err error) {
+	if len(rpID) == 0 {
+		return nil, 0, fmt.Errorf("rpID is empty %s", common.GetLine())
+	}
+	if len(authenticatorData) < authenticatorDataMinLength {
+		return nil, 0, fmt.Errorf("authenticatorData is too short %s", common.GetLine())
+	}
+
+	// AUTHENTICATOR DATA
+	rpIDHash := sha256.Sum256([]byte(rpID))
+	if !bytes.Equal(rpIDHash[:], authenticatorData[:32]) {
+		return nil, 0, fmt.Errorf("The assertion wasn't issued for rpID %s %s", rpID, common.GetLine())
+	}
+	flags := authenticatorData[32]
+	if flags&FlagUserPresent == 0 {
+		return nil, 0, fmt.Errorf("The user wasn't present %s", common.GetLine())
+	}
+	if requireUserVerification && flags&FlagUserVerified == 0 {
+		return nil, 0, fmt.Errorf("The user wasn't verified %s", common.GetLine())
+	}
+	signCount = binary.BigEndian.Uint32(authenticatorData[33:37])
+
+	// CLIENT DATA
+	var client clientData
+	err = json.Unmarshal(clientDataJSON, &client)
+	if err != nil {
+		return nil, 0, fmt.Errorf("Can't parse clientDataJSON. Cause: %s %s", err.Error(), common.GetLine())
+	}
+	if client.Type != "webauthn.get" {
+		return nil, 0, fmt.Errorf("Invalid clientDataJSON type %s %s", client.Type, common.GetLine())
+	}
+	signedChallenge, err := DecodeBase64(client.Challenge)
+	if err != nil {
+		return nil, 0, fmt.Errorf("Can't decode the challenge. Cause: %s %s", err.Error(), common.GetLine())
+	}
+	if !bytes.Equal(signedChallenge, challenge) {
+		return nil, 0, fmt.Errorf("The assertion wasn't issued for this challenge %s", common.GetLine())
+	}
+
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	signedData = make([]byte, 0, len(authenticatorData)+len(clientDataHash))
+	signedData = append(signedData, authenticatorData...)
+	signedData = append(signedData, clientDataHash[:]...)
+	return signedData, signCount, nil
+}
+
+// CheckSignCount func to detect cloned authenticators: the counter must increase at every assertion,
+// unless the authenticator doesn't implement it and always returns 0
+func CheckSignCount(stored uint32, received uint32) error {
+	if stored == 0 && received == 0 {
+		return nil
+	}
+	if received <= stored {
+		return fmt.Errorf("The signature counter %d isn't greater than %d, the authenticator may be cloned %s", received, stored, common.GetLine())
+	}
+	return nil
+}
+
+// DecodeBase64 func to decode standard or URL base64, with or without padding
+func DecodeBase64(s string) ([]byte, error) {
+	s = strings.TrimRight(s, "=")
+	if strings.ContainsAny(s, "-_") {
+		return base64.RawURLEncoding.DecodeString(s)
+	}
+	return base64.RawStdEncoding.DecodeString(s)
+}
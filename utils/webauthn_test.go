@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+// assertion builds the authenticator data and client data of a WebAuthn assertion
+func assertion(rpID string, flags byte, signCount byte, challenge []byte) ([]byte, []byte) {
+	rpIDHash := sha256.Sum256([]byte(rpID))
+	authenticatorData := append(rpIDHash[:], flags, 0, 0, 0, signCount)
+	clientDataJSON := []byte(`{"type":"webauthn.get","challenge":"` + base64.RawURLEncoding.EncodeToString(challenge) + `","origin":"https://` + rpID + `"}`)
+	return authenticatorData, clientDataJSON
+}
+
+func TestCheckWebAuthnAssertion(t *testing.T) {
+	challenge := sha256.Sum256([]byte("payload"))
+
+	authenticatorData, clientDataJSON := assertion("hstx.akachain.io", FlagUserPresent|FlagUserVerified, 7, challenge[:])
+	signedData, signCount, err := CheckWebAuthnAssertion(authenticatorData, clientDataJSON, "hstx.akachain.io", challenge[:], true)
+	assert.NilError(t, err)
+	assert.Equal(t, uint32(7), signCount)
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	assert.DeepEqual(t, append(authenticatorData, clientDataHash[:]...), signedData)
+
+	// Another relying party
+	_, _, err = CheckWebAuthnAssertion(authenticatorData, clientDataJSON, "evil.example.com", challenge[:], true)
+	assert.ErrorContains(t, err, "rpID")
+
+	// Another challenge
+	other := sha256.Sum256([]byte("other payload"))
+	_, _, err = CheckWebAuthnAssertion(authenticatorData, clientDataJSON, "hstx.akachain.io", other[:], true)
+	assert.ErrorContains(t, err, "challenge")
+
+	// User present but not verified
+	authenticatorData, clientDataJSON = assertion("hstx.akachain.io", FlagUserPresent, 8, challenge[:])
+	_, _, err = CheckWebAuthnAssertion(authenticatorData, clientDataJSON, "hstx.akachain.io", challenge[:], true)
+	assert.ErrorContains(t, err, "verified")
+	_, _, err = CheckWebAuthnAssertion(authenticatorData, clientDataJSON, "hstx.akachain.io", challenge[:], false)
+	assert.NilError(t, err)
+}
+
+func TestCheckSignCount(t *testing.T) {
+	assert.NilError(t, CheckSignCount(0, 0))
+	assert.NilError(t, CheckSignCount(6, 7))
+	assert.ErrorContains(t, CheckSignCount(7, 7), "cloned")
+	assert.ErrorContains(t, CheckSignCount(8, 7), "cloned")
+}